@@ -5,7 +5,9 @@ go 1.25.0
 require (
 	github.com/fatih/color v1.18.0 // direct
 	github.com/goccy/go-yaml v1.19.0 // direct
+	github.com/pelletier/go-toml/v2 v2.3.1 // direct
 	github.com/spf13/cobra v1.10.2 // direct
+	go.starlark.net v0.0.0-20231121155337-90ade8b19d09 // direct
 )
 
 require (
@@ -1,10 +1,16 @@
 package cmdctx
 
 import (
+	"fmt"
+	"os"
+
+	"github.com/goccy/go-yaml"
+
 	"github.com/m-mdy-m/psx/internal/config"
 	"github.com/m-mdy-m/psx/internal/flags"
 	"github.com/m-mdy-m/psx/internal/logger"
 	"github.com/m-mdy-m/psx/internal/resources"
+	"github.com/m-mdy-m/psx/internal/utils"
 )
 
 type ProjectContext struct {
@@ -33,6 +39,10 @@ func LoadProject(args []string) (*ProjectContext, error) {
 
 	// Load configuration
 	logger.Verbose("Loading configuration...")
+	config.UseProfile(f.GlobalFlags.Profile)
+	if err := config.UseOverrides(f.GlobalFlags.Set); err != nil {
+		return nil, logger.Errorf("%v", err)
+	}
 	cfg, err := config.Load(f.GlobalFlags.ConfigFile, pathCtx.Abs)
 	if err != nil {
 		return nil, logger.Errorf("config load failed: %w", err)
@@ -47,13 +57,19 @@ func LoadProject(args []string) (*ProjectContext, error) {
 	logger.Verbosef("Project type: %s", projectType)
 	logger.Verbosef("Active rules: %d", len(cfg.ActiveRules))
 
+	if err := useAnswers(cfg, f); err != nil {
+		return nil, logger.Errorf("%v", err)
+	}
+
 	// Get project info
 	interactive := false
 	if f.Fix.Interactive || f.Fix.All {
 		interactive = true
 	}
 
-	projectInfo := resources.GetProjectInfo(pathCtx.Abs, interactive)
+	// a preview, or a patch of the changes, leaves the project untouched
+	save := !f.Fix.DryRun && f.Fix.Output != "patch"
+	projectInfo := resources.GetProjectInfo(pathCtx.Abs, interactive, save)
 	if projectInfo == nil {
 		logger.Warning("Could not get project info, using defaults")
 		projectInfo = &resources.ProjectInfo{
@@ -71,3 +87,37 @@ func LoadProject(args []string) (*ProjectContext, error) {
 		ProjectInfo: projectInfo,
 	}, nil
 }
+
+// useAnswers answers fix questions from psx.yml, then the answers file,
+// then --answer, each overriding the one before
+func useAnswers(cfg *config.Config, f *flags.Flags) error {
+	utils.UseAnswers(cfg.Answers)
+
+	if f.Fix.AnswersFile != "" {
+		data, err := os.ReadFile(f.Fix.AnswersFile)
+		if err != nil {
+			return fmt.Errorf("failed to read answers file: %w", err)
+		}
+		fileAnswers := map[string]string{}
+		if err := yaml.Unmarshal(data, &fileAnswers); err != nil {
+			return fmt.Errorf("%s: %w", f.Fix.AnswersFile, err)
+		}
+		for key, value := range fileAnswers {
+			if err := utils.ValidateAnswer(key, value); err != nil {
+				return fmt.Errorf("%s: %w", f.Fix.AnswersFile, err)
+			}
+		}
+		utils.UseAnswers(fileAnswers)
+	}
+
+	for _, answer := range f.Fix.Answers {
+		key, value, err := utils.ParseAnswer(answer)
+		if err != nil {
+			return err
+		}
+		utils.UseAnswers(map[string]string{key: value})
+	}
+
+	utils.SetNonInteractive(f.Fix.NonInteractive)
+	return nil
+}
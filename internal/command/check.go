@@ -3,10 +3,12 @@ package command
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/m-mdy-m/psx/internal/cmdctx"
+	"github.com/m-mdy-m/psx/internal/config"
 	"github.com/m-mdy-m/psx/internal/flags"
 	"github.com/m-mdy-m/psx/internal/logger"
 	"github.com/m-mdy-m/psx/internal/reporter"
@@ -24,7 +26,8 @@ Examples:
   psx check                       # Check current directory
   psx check ./my-project          # Check specific directory
   psx check --verbose             # Show detailed information
-  psx check --output json         # JSON output for CI/CD`,
+  psx check --output json         # JSON output for CI/CD
+  psx check --as-of 2027-01-01    # Preview scheduled severities`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runCheckCommand,
 }
@@ -41,15 +44,28 @@ func init() {
 
 	CheckCmd.Flags().StringVar(&f.Check.FailOn, "fail-on", df.FailOn,
 		"exit with error on: error | warning")
+
+	CheckCmd.Flags().StringVar(&f.Check.AsOf, "as-of", df.AsOf,
+		"resolve severity schedules and suppression expiry as of this date (YYYY-MM-DD)")
 }
 
 func runCheckCommand(cmd *cobra.Command, args []string) error {
+	f := flags.GetFlags()
+	if f.Check.AsOf != "" {
+		asOf, err := time.ParseInLocation(time.DateOnly, f.Check.AsOf, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --as-of date '%s' - use YYYY-MM-DD", f.Check.AsOf)
+		}
+		config.SetNow(asOf)
+		logger.Verbose(fmt.Sprintf("Resolving schedules as of %s", f.Check.AsOf))
+	}
+
 	ctx, err := cmdctx.LoadProject(args)
 	if err != nil {
 		return err
 	}
+	applyCheckDefaults(cmd, ctx.Config)
 
-	f := flags.GetFlags()
 	logger.Verbose(resources.FormatMessage("check", "start", ctx.Path.Abs))
 	logger.Verbose(fmt.Sprintf("Project type: %s", ctx.ProjectType))
 	logger.Verbose(fmt.Sprintf("Active rules: %d", len(ctx.Config.ActiveRules)))
@@ -66,13 +82,61 @@ func runCheckCommand(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
+	// generating the files again must not ask anything
+	utils.SetNonInteractive(true)
+	result.Drift, err = rules.CheckDrift(rulesCtx)
+	if err != nil {
+		logger.Warning(fmt.Sprintf("Failed to check generated files against their templates: %v", err))
+	}
 	rep := reporter.New(f.Check.OutputFormat, result)
 	if err := rep.Report(); err != nil {
 		return fmt.Errorf("report generation failed: %w", err)
 	}
+	// JSON output carries these itself
+	if f.Check.OutputFormat != "json" {
+		warnStaleSuppressions(result)
+		warnDrift(result)
+	}
 	return determineExitCode(result, f.Check.FailOn)
 }
 
+// applyCheckDefaults uses fail_on and output from the profile or an
+// override unless the flags were given explicitly
+func applyCheckDefaults(cmd *cobra.Command, cfg *config.Config) {
+	f := flags.GetFlags()
+	if cfg.Profile != "" {
+		logger.Verbose(fmt.Sprintf("Profile: %s", cfg.Profile))
+	}
+
+	if cfg.FailOn != "" && !cmd.Flags().Changed("fail-on") {
+		f.Check.FailOn = cfg.FailOn
+	}
+	if cfg.Output != "" && !cmd.Flags().Changed("output") {
+		f.Check.OutputFormat = cfg.Output
+	}
+}
+
+func warnStaleSuppressions(result *rules.ExecutionResult) {
+	for _, s := range result.ExpiredSuppressions {
+		logger.Warning(resources.FormatMessage("check", "suppression_expired", s, s.Expires))
+	}
+	for _, s := range result.UnusedSuppressions {
+		logger.Warning(resources.FormatMessage("check", "suppression_unused", s))
+	}
+}
+
+// warnDrift points out generated files that 'psx fix --update' would bring
+// up to date with their template
+func warnDrift(result *rules.ExecutionResult) {
+	for _, d := range result.Drift {
+		key := "template_drift"
+		if d.Edited {
+			key = "template_drift_edited"
+		}
+		logger.Warning(resources.FormatMessage("check", key, d.Path, d.Template))
+	}
+}
+
 func determineExitCode(result *rules.ExecutionResult, failOn string) error {
 	f := flags.GetFlags()
 
@@ -0,0 +1,151 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/m-mdy-m/psx/internal/cmdctx"
+	"github.com/m-mdy-m/psx/internal/config"
+	"github.com/m-mdy-m/psx/internal/flags"
+	"github.com/m-mdy-m/psx/internal/logger"
+	"github.com/m-mdy-m/psx/internal/resources"
+	"github.com/m-mdy-m/psx/internal/utils"
+)
+
+var ConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect, validate and edit psx configuration",
+	Long: `Inspect, validate and edit psx configuration.
+
+A project's config is built from, lowest precedence first: the presets
+and files its config file extends, the config file, and the selected
+profile. The config file is the project's psx.yml, or the one in the git
+root above it. Only when the project has none is ~/.config/psx/psx.yml
+used instead, and only when that is missing too the built-in defaults.
+
+In each directory psx looks for psx.yml, .psx.yml, psx.yaml, .psx.yaml,
+psx.json, .psx.json, psx.toml, .psx.toml and the "psx" key of package.json.
+All of them hold the same settings. A directory with more than one of them
+is an error.
+
+Examples:
+  psx config show --resolved              # Merged config with sources
+  psx config validate                     # Check psx.yml and nested configs
+  psx config migrate                      # Upgrade to the current version
+  psx config set rules.changelog warning  # Edit psx.yml, keeping comments
+  psx config disable dockerfile           # Same as rules.dockerfile: false
+  psx config add-ignore tmp/              # Add to the ignore list
+  psx config schema > psx.schema.json     # JSON Schema for editors`,
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema for psx.yml",
+	Long: `Print a JSON Schema for psx.yml, including every built-in rule ID.
+
+Point your editor at it for completion and inline validation, e.g. with the
+YAML language server add this as the first line of psx.yml:
+
+  # yaml-language-server: $schema=./psx.schema.json
+
+or in psx.json:
+
+  "$schema": "./psx.schema.json"`,
+	Args: cobra.NoArgs,
+	RunE: runConfigSchemaCommand,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [path]",
+	Short: "Report every problem in the configuration",
+	Long: `Validate psx.yml, the files it extends and nested configs, and report
+every error and warning with its line and column.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConfigValidateCommand,
+}
+
+func init() {
+	ConfigCmd.AddCommand(configSchemaCmd)
+	ConfigCmd.AddCommand(configValidateCmd)
+}
+
+func runConfigSchemaCommand(cmd *cobra.Command, args []string) error {
+	data, err := json.MarshalIndent(config.Schema(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode schema: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func runConfigValidateCommand(cmd *cobra.Command, args []string) error {
+	pathCtx, err := cmdctx.ResolvePath(args)
+	if err != nil {
+		return err
+	}
+
+	f := flags.GetFlags()
+	configFile := f.GlobalFlags.ConfigFile
+	if configFile == "" {
+		configFile, err = config.FindConfigFile(pathCtx.Abs)
+		if errors.Is(err, config.ErrNoConfigFile) {
+			return logger.Errorf("%s", resources.GetMessage("errors", "config_not_found"))
+		}
+		if err != nil {
+			return logger.Errorf("%v", err)
+		}
+	}
+
+	config.UseProfile(f.GlobalFlags.Profile)
+	if err := config.UseOverrides(f.GlobalFlags.Set); err != nil {
+		return logger.Errorf("%v", err)
+	}
+	issues := config.ValidateFiles(configFile, pathCtx.Abs)
+
+	errorCount, warningCount := 0, 0
+	for _, issue := range issues {
+		if issue.Severity == config.SeverityError {
+			errorCount++
+		} else {
+			warningCount++
+		}
+		printIssue(issue)
+	}
+
+	if len(issues) == 0 {
+		logger.Success(fmt.Sprintf("%s is valid", configFile))
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Printf("%d errors, %d warnings\n", errorCount, warningCount)
+	if errorCount > 0 {
+		os.Exit(utils.ExitConfig)
+	}
+	return nil
+}
+
+func printIssue(issue config.Issue) {
+	f := flags.GetFlags()
+
+	label := string(issue.Severity)
+	if !f.GlobalFlags.NoColor {
+		c := color.New(color.FgYellow)
+		if issue.Severity == config.SeverityError {
+			c = color.New(color.FgRed)
+		}
+		label = c.Sprint(label)
+	}
+
+	message := issue.Message
+	if issue.Field != "" && !strings.Contains(message, issue.Field) {
+		message = fmt.Sprintf("%s (%s)", message, issue.Field)
+	}
+	fmt.Printf("%s: %s: %s\n", issue.Location(), label, message)
+}
@@ -0,0 +1,177 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/m-mdy-m/psx/internal/cmdctx"
+	"github.com/m-mdy-m/psx/internal/config"
+	"github.com/m-mdy-m/psx/internal/flags"
+	"github.com/m-mdy-m/psx/internal/logger"
+	"github.com/m-mdy-m/psx/internal/utils"
+)
+
+const configEditNote = `
+The project's YAML config is edited in place, so comments, key order and
+layout are kept. The result is validated before it is written; a project
+without a config gets a new psx.yml.`
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a value in psx.yml",
+	Long: `Set a dotted key in psx.yml. The value is read as YAML, like --set.
+` + configEditNote + `
+
+Examples:
+  psx config set rules.changelog warning
+  psx config set project.type go
+  psx config set profiles.ci.fail_on warning`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return editConfig(fmt.Sprintf("Set %s", args[0]), func(edit *config.Edit) error {
+			return edit.Set(args[0], config.ParseValue(args[1]))
+		})
+	},
+}
+
+var configEnableCmd = &cobra.Command{
+	Use:   "enable <rule> [severity]",
+	Short: "Enable a rule in psx.yml",
+	Long: `Enable a rule, at its default severity unless one is given.
+` + configEditNote + `
+
+Examples:
+  psx config enable changelog
+  psx config enable dockerfile warning`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		severity := string(config.SeverityError)
+		if meta, ok := config.GetRulesMetadata().Rules[args[0]]; ok {
+			severity = string(meta.DefaultSeverity)
+		}
+		if len(args) == 2 {
+			severity = args[1]
+		}
+		return editConfig(fmt.Sprintf("Enabled %s (%s)", args[0], severity), func(edit *config.Edit) error {
+			return edit.Set("rules."+args[0], severity)
+		})
+	},
+}
+
+var configDisableCmd = &cobra.Command{
+	Use:   "disable <rule>",
+	Short: "Disable a rule in psx.yml",
+	Long: `Disable a rule by setting it to false.
+` + configEditNote + `
+
+Examples:
+  psx config disable dockerfile`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return editConfig(fmt.Sprintf("Disabled %s", args[0]), func(edit *config.Edit) error {
+			return edit.Set("rules."+args[0], false)
+		})
+	},
+}
+
+var configAddIgnoreCmd = &cobra.Command{
+	Use:   "add-ignore <pattern>...",
+	Short: "Add ignore patterns to psx.yml",
+	Long: `Add patterns to the ignore list. Patterns already in it are skipped.
+` + configEditNote + `
+
+Examples:
+  psx config add-ignore tmp/
+  psx config add-ignore "vendor/**" build/`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return editConfig("Updated ignore", func(edit *config.Edit) error {
+			return edit.Append("ignore", args...)
+		})
+	},
+}
+
+func init() {
+	f := flags.GetFlags()
+	df := flags.DefaultValues.Config
+
+	for _, cmd := range []*cobra.Command{configSetCmd, configEnableCmd, configDisableCmd, configAddIgnoreCmd} {
+		cmd.Flags().BoolVar(&f.Config.DryRun, "dry-run", df.DryRun,
+			"show the change without writing it")
+		ConfigCmd.AddCommand(cmd)
+	}
+}
+
+// editConfig applies change to the project's config file, validates the
+// result and writes it
+func editConfig(summary string, change func(edit *config.Edit) error) error {
+	pathCtx, err := cmdctx.ResolvePath(nil)
+	if err != nil {
+		return err
+	}
+
+	configFile, err := editableConfigFile(pathCtx.Abs)
+	if err != nil {
+		return logger.Errorf("%v", err)
+	}
+	edit, err := config.EditFile(configFile)
+	if err != nil {
+		return logger.Errorf("%v", err)
+	}
+	if err := change(edit); err != nil {
+		return logger.Errorf("%v", err)
+	}
+
+	name := migrationName(edit.File, pathCtx.Abs)
+	if !edit.Changed() {
+		logger.Info(fmt.Sprintf("%s already has this setting", name))
+		return nil
+	}
+
+	if issues := edit.Validate(pathCtx.Abs); len(issues) > 0 {
+		for _, issue := range issues {
+			printIssue(issue)
+		}
+		logger.Error(fmt.Sprintf("%s was not changed", name))
+		os.Exit(utils.ExitConfig)
+	}
+
+	if flags.GetFlags().Config.DryRun {
+		oldName := "a/" + name
+		if edit.Created {
+			oldName = "/dev/null"
+		}
+		fmt.Print(utils.UnifiedDiff(oldName, "b/"+name, string(edit.Before), string(edit.After)))
+		return nil
+	}
+	if err := edit.Save(); err != nil {
+		return logger.Errorf("%v", err)
+	}
+	logger.Success(fmt.Sprintf("%s in %s", summary, name))
+	return nil
+}
+
+// editableConfigFile picks the file to edit: --config, else the project's
+// own config. The home config is never edited implicitly; a project that
+// only has one gets a new psx.yml
+func editableConfigFile(root string) (string, error) {
+	f := flags.GetFlags()
+	if f.GlobalFlags.ConfigFile != "" {
+		return f.GlobalFlags.ConfigFile, nil
+	}
+
+	file, err := config.FindConfigFile(root)
+	switch {
+	case errors.Is(err, config.ErrNoConfigFile):
+		return filepath.Join(root, config.DefaultConfigFile), nil
+	case err != nil:
+		return "", err
+	case file == config.HomeConfigFile():
+		return filepath.Join(root, config.DefaultConfigFile), nil
+	}
+	return file, nil
+}
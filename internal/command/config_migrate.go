@@ -0,0 +1,133 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/m-mdy-m/psx/internal/cmdctx"
+	"github.com/m-mdy-m/psx/internal/config"
+	"github.com/m-mdy-m/psx/internal/flags"
+	"github.com/m-mdy-m/psx/internal/logger"
+	"github.com/m-mdy-m/psx/internal/resources"
+	"github.com/m-mdy-m/psx/internal/utils"
+)
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate [path]",
+	Short: "Upgrade config files to the current version",
+	Long: fmt.Sprintf(`Rewrite the project's config file and nested configs to config
+version %d: rename rule IDs that have changed and update version.
+
+YAML files are edited in place, so comments and key order are kept. A diff
+is shown before anything is written. Other formats are listed with the
+changes to make by hand.
+
+Examples:
+  psx config migrate              # Show the diff and ask before writing
+  psx config migrate --dry-run    # Only show the diff
+  psx config migrate --yes        # Write without asking`, config.CurrentVersion),
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConfigMigrateCommand,
+}
+
+func init() {
+	f := flags.GetFlags()
+	df := flags.DefaultValues.Config
+
+	configMigrateCmd.Flags().BoolVar(&f.Config.DryRun, "dry-run", df.DryRun,
+		"show the changes without writing them")
+	configMigrateCmd.Flags().BoolVarP(&f.Config.Yes, "yes", "y", df.Yes,
+		"write the changes without asking")
+
+	ConfigCmd.AddCommand(configMigrateCmd)
+}
+
+func runConfigMigrateCommand(cmd *cobra.Command, args []string) error {
+	pathCtx, err := cmdctx.ResolvePath(args)
+	if err != nil {
+		return err
+	}
+
+	f := flags.GetFlags()
+	configFile := f.GlobalFlags.ConfigFile
+	if configFile == "" {
+		configFile, err = config.FindConfigFile(pathCtx.Abs)
+		if errors.Is(err, config.ErrNoConfigFile) {
+			return logger.Errorf("%s", resources.GetMessage("errors", "config_not_found"))
+		}
+		if err != nil {
+			return logger.Errorf("%v", err)
+		}
+	}
+
+	files, err := config.MigrationFiles(configFile, pathCtx.Abs)
+	if err != nil {
+		return logger.Errorf("%v", err)
+	}
+
+	pending := []*config.Migration{}
+	failed := 0
+	for _, file := range files {
+		m, err := config.Migrate(file)
+		if err != nil {
+			logger.Error(err.Error())
+			failed++
+			continue
+		}
+		if !m.Changed() {
+			logger.Verbose(fmt.Sprintf("%s is up to date", file))
+			continue
+		}
+		pending = append(pending, m)
+
+		name := migrationName(file, pathCtx.Abs)
+		fmt.Print(utils.UnifiedDiff("a/"+name, "b/"+name, string(m.Before), string(m.After)))
+		fmt.Println()
+	}
+
+	if len(pending) == 0 {
+		if failed > 0 {
+			os.Exit(utils.ExitConfig)
+		}
+		logger.Success(fmt.Sprintf("Config is up to date (version %d)", config.CurrentVersion))
+		return nil
+	}
+
+	if f.Config.DryRun {
+		logger.Info("Run without --dry-run to write these changes")
+		return nil
+	}
+	if !f.Config.Yes && !utils.Prompt(fmt.Sprintf("Write changes to %d file(s)?", len(pending))) {
+		logger.Info("No changes written")
+		return nil
+	}
+
+	for _, m := range pending {
+		info, err := os.Stat(m.File)
+		if err != nil {
+			return logger.Errorf("failed to write %s: %w", m.File, err)
+		}
+		if err := os.WriteFile(m.File, m.After, info.Mode().Perm()); err != nil {
+			return logger.Errorf("failed to write %s: %w", m.File, err)
+		}
+		logger.Success(fmt.Sprintf("Migrated %s to version %d", migrationName(m.File, pathCtx.Abs), config.CurrentVersion))
+	}
+	if failed > 0 {
+		os.Exit(utils.ExitConfig)
+	}
+	return nil
+}
+
+// migrationName shows a config file relative to the project when it is in it
+func migrationName(file, root string) string {
+	rel, err := filepath.Rel(root, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return file
+	}
+	return filepath.ToSlash(rel)
+}
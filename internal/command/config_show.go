@@ -0,0 +1,327 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/spf13/cobra"
+
+	"github.com/m-mdy-m/psx/internal/cmdctx"
+	"github.com/m-mdy-m/psx/internal/config"
+	"github.com/m-mdy-m/psx/internal/flags"
+	"github.com/m-mdy-m/psx/internal/logger"
+)
+
+var configShowCmd = &cobra.Command{
+	Use:   "show [path]",
+	Short: "Show the configuration in use",
+	Long: `Show the configuration file psx uses for a project.
+
+With --resolved, print the fully merged configuration instead: every value
+annotated with the file, preset, profile or flag it came from, and every
+rule marked as active, disabled or unknown.
+
+Examples:
+  psx config show                         # Print the config file
+  psx config show --resolved              # Merged config with sources
+  psx config show --resolved --profile ci # As CI would see it`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConfigShowCommand,
+}
+
+func init() {
+	f := flags.GetFlags()
+	df := flags.DefaultValues.Config
+
+	configShowCmd.Flags().BoolVar(&f.Config.Resolved, "resolved", df.Resolved,
+		"show the merged configuration with the source of every value")
+
+	ConfigCmd.AddCommand(configShowCmd)
+}
+
+func runConfigShowCommand(cmd *cobra.Command, args []string) error {
+	pathCtx, err := cmdctx.ResolvePath(args)
+	if err != nil {
+		return err
+	}
+
+	f := flags.GetFlags()
+	configFile := f.GlobalFlags.ConfigFile
+	if configFile == "" {
+		configFile, err = config.FindConfigFile(pathCtx.Abs)
+		if err != nil && !errors.Is(err, config.ErrNoConfigFile) {
+			return logger.Errorf("%v", err)
+		}
+	}
+
+	if !f.Config.Resolved {
+		if configFile == "" {
+			fmt.Printf("No config file found, using built-in defaults (%s)\n", config.DefaultsSource)
+			return nil
+		}
+		data, err := config.ReadConfigData(configFile)
+		if err != nil {
+			return logger.Errorf("failed to read %s: %w", configFile, err)
+		}
+		fmt.Printf("# %s\n%s", configFile, data)
+		return nil
+	}
+
+	config.UseProfile(f.GlobalFlags.Profile)
+	if err := config.UseOverrides(f.GlobalFlags.Set); err != nil {
+		return logger.Errorf("%v", err)
+	}
+	cfg, err := config.Load(configFile, pathCtx.Abs)
+	if err != nil {
+		return logger.Errorf("config load failed: %w", err)
+	}
+
+	fmt.Println()
+	printConfigLayers(cfg, pathCtx.Abs)
+	printConfigSettings(cfg)
+	printConfigRules(cfg)
+	printConfigScopes(cfg)
+	return nil
+}
+
+func printConfigLayers(cfg *config.Config, root string) {
+	f := flags.GetFlags()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	switch {
+	case cfg.File == "":
+		fmt.Fprintf(w, "Config file:\tnone, using %s\n", config.DefaultsSource)
+	case f.GlobalFlags.ConfigFile != "":
+		fmt.Fprintf(w, "Config file:\t%s\t(--config)\n", cfg.File)
+	case cfg.File == config.HomeConfigFile():
+		fmt.Fprintf(w, "Config file:\t%s\t(home config, the project has none)\n", cfg.File)
+	default:
+		fmt.Fprintf(w, "Config file:\t%s\n", cfg.File)
+	}
+
+	switch {
+	case cfg.Profile == "":
+		fmt.Fprintf(w, "Profile:\tnone\n")
+	case f.GlobalFlags.Profile != "":
+		fmt.Fprintf(w, "Profile:\t%s\t(--profile)\n", cfg.Profile)
+	case os.Getenv("CI") != "":
+		fmt.Fprintf(w, "Profile:\t%s\t($CI is set)\n", cfg.Profile)
+	default:
+		fmt.Fprintf(w, "Profile:\t%s\t(default outside CI)\n", cfg.Profile)
+	}
+	w.Flush()
+
+	if cfg.File != "" {
+		fmt.Println("Layers (lowest precedence first):")
+		for i, layer := range config.Layers(cfg.File, root) {
+			fmt.Printf("  %d. %s\n", i+1, layer)
+		}
+	}
+
+	if overrides := config.Overrides(); len(overrides) > 0 {
+		fmt.Println("Overrides (win over every layer):")
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, override := range overrides {
+			value := fmt.Sprint(override.Value)
+			if list, ok := override.Value.([]any); ok {
+				value = formatSettingList(list)
+			}
+			fmt.Fprintf(w, "  %s=%s\t%s\n", override.Key, value, override.Source)
+		}
+		w.Flush()
+	}
+	fmt.Println()
+}
+
+func printConfigSettings(cfg *config.Config) {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return
+	}
+	raw := map[string]any{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return
+	}
+	delete(raw, "rules")
+
+	settings := map[string]string{}
+	flattenSettings(raw, "", settings)
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Println("Settings:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, key := range keys {
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", key, settings[key], cfg.Source(key))
+	}
+
+	// check flags that profiles and overrides can set
+	df := flags.DefaultValues.Check
+	failOn, failOnSource := checkSetting(cfg, config.SettingFailOn, cfg.FailOn, df.FailOn)
+	output, outputSource := checkSetting(cfg, config.SettingOutput, cfg.Output, df.OutputFormat)
+	fmt.Fprintf(w, "  check --fail-on\t%s\t%s\n", failOn, failOnSource)
+	fmt.Fprintf(w, "  check --output\t%s\t%s\n", output, outputSource)
+	w.Flush()
+	fmt.Println()
+}
+
+// checkSetting returns a check flag default and where it came from
+func checkSetting(cfg *config.Config, key, value, fallback string) (string, string) {
+	switch {
+	case value == "":
+		return fallback, "default"
+	case cfg.Sources[key] != "":
+		return value, cfg.Sources[key]
+	default:
+		return value, "profile " + cfg.Profile
+	}
+}
+
+// flattenSettings turns nested maps into dotted keys. Lists are shown
+// inline, or as an entry count when they hold objects
+func flattenSettings(raw map[string]any, prefix string, into map[string]string) {
+	for key, value := range raw {
+		path := prefix + key
+		switch v := value.(type) {
+		case map[string]any:
+			if len(v) == 0 {
+				continue
+			}
+			flattenSettings(v, path+".", into)
+		case []any:
+			into[path] = formatSettingList(v)
+		case nil:
+			continue
+		default:
+			into[path] = fmt.Sprint(v)
+		}
+	}
+}
+
+func formatSettingList(list []any) string {
+	items := make([]string, 0, len(list))
+	for _, item := range list {
+		switch item.(type) {
+		case map[string]any, []any:
+			return fmt.Sprintf("(%d entries)", len(list))
+		default:
+			items = append(items, fmt.Sprint(item))
+		}
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+func printConfigRules(cfg *config.Config) {
+	metadata := config.GetRulesMetadata()
+
+	active := make([]string, 0, len(cfg.ActiveRules))
+	for id := range cfg.ActiveRules {
+		active = append(active, id)
+	}
+	sort.Strings(active)
+
+	disabled := map[string]string{}
+	unknown := []string{}
+	for id, severity := range cfg.Rules {
+		if _, exists := cfg.ActiveRules[id]; exists {
+			continue
+		}
+		if _, exists := metadata.Rules[id]; !exists {
+			unknown = append(unknown, id)
+			continue
+		}
+		if next := config.NextEscalation(severity); next != nil {
+			disabled[id] = fmt.Sprintf("starts as %s on %s", next.Severity, next.Date.Format(time.DateOnly))
+		} else {
+			disabled[id] = fmt.Sprint(severity)
+		}
+	}
+	if len(cfg.Rules) > 0 {
+		for id := range metadata.Rules {
+			if _, exists := cfg.Rules[id]; !exists {
+				disabled[id] = "not listed in rules"
+			}
+		}
+	}
+	if cfg.Custom != nil {
+		for _, rule := range cfg.Custom.Rules {
+			if _, exists := cfg.ActiveRules[rule.ID]; !exists {
+				disabled[rule.ID] = fmt.Sprint(rule.Severity)
+			}
+		}
+		for _, plugin := range cfg.Custom.Plugins {
+			if _, exists := cfg.ActiveRules[plugin.Name]; !exists {
+				disabled[plugin.Name] = fmt.Sprint(plugin.Severity)
+			}
+		}
+	}
+	sort.Strings(unknown)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Active rules (%d):\n", len(active))
+	for _, id := range active {
+		rule := cfg.ActiveRules[id]
+		line := fmt.Sprintf("  %s\t%s\t%s", id, rule.Severity, rule.Source)
+		if e := rule.Escalation; e != nil {
+			line += fmt.Sprintf("\tbecomes %s on %s", e.Severity, e.Date.Format(time.DateOnly))
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	if len(disabled) > 0 {
+		ids := make([]string, 0, len(disabled))
+		for id := range disabled {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		fmt.Fprintf(w, "\nDisabled rules (%d):\n", len(ids))
+		for _, id := range ids {
+			source := "-"
+			if _, listed := cfg.Rules[id]; listed {
+				source = cfg.Source("rules." + id)
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", id, disabled[id], source)
+		}
+	}
+
+	if len(unknown) > 0 {
+		fmt.Fprintf(w, "\nUnknown rules (%d):\n", len(unknown))
+		for _, id := range unknown {
+			fmt.Fprintf(w, "  %s\t%v\t%s\n", id, cfg.Rules[id], cfg.Source("rules."+id))
+		}
+	}
+	w.Flush()
+}
+
+func printConfigScopes(cfg *config.Config) {
+	scopes := []*config.Config{}
+	var walk func([]*config.Config)
+	walk = func(list []*config.Config) {
+		for _, scope := range list {
+			scopes = append(scopes, scope)
+			walk(scope.Scopes)
+		}
+	}
+	walk(cfg.Scopes)
+	if len(scopes) == 0 {
+		return
+	}
+
+	fmt.Printf("\nNested configs (%d):\n", len(scopes))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, scope := range scopes {
+		fmt.Fprintf(w, "  %s/\t%s\t%d active rules\n", scope.Scope, scope.File, len(scope.ActiveRules))
+	}
+	w.Flush()
+}
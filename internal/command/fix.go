@@ -2,14 +2,23 @@ package command
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 
 	"github.com/m-mdy-m/psx/internal/cmdctx"
+	"github.com/m-mdy-m/psx/internal/config"
 	"github.com/m-mdy-m/psx/internal/flags"
+	"github.com/m-mdy-m/psx/internal/history"
 	"github.com/m-mdy-m/psx/internal/logger"
 	"github.com/m-mdy-m/psx/internal/resources"
 	"github.com/m-mdy-m/psx/internal/rules"
+	"github.com/m-mdy-m/psx/internal/utils"
 )
 
 var FixCmd = &cobra.Command{
@@ -17,12 +26,63 @@ var FixCmd = &cobra.Command{
 	Short: "Fix structural issues",
 	Long: `Automatically fix common structural issues in your project.
 
+An existing .gitignore or .dockerignore is not replaced: entries missing
+from psx's template are appended under a "# Added by psx" section. Missing
+package.json fields are added without reordering or reformatting the file.
+
+--dry-run shows every planned change as a unified diff: new files against
+/dev/null, edits and deletions against the current file. The output goes
+through $PAGER (less by default) on a terminal; --no-pager turns that off.
+--stat shows the number of changed lines per file instead.
+
+--output patch prints the planned changes as a git patch instead and
+changes nothing, so they can be reviewed or applied with 'git apply'.
+Everything else psx prints goes to stderr.
+
+Questions psx asks while generating files have keys, so they can be
+answered ahead of time: answers: in psx.yml, --answers-file, or --answer,
+each overriding the one before. Keys: project_name, description, author,
+email, github_user, repo_name, license, docker_compose_db (yes, no) and
+ci_platform (github, gitlab, both, skip). With --non-interactive psx never
+prompts and fails, before changing anything, listing the unanswered keys.
+
+--branch creates a git branch for the changes and --commit commits them,
+in one commit or with --commit=per-rule one per rule, authored by the git
+config user. Both refuse to run with uncommitted changes unless
+--allow-dirty is given; only the files psx changed are committed, along
+with the record of generated files below.
+
+Files psx generates from its templates are recorded in .psx/managed.yml,
+which belongs in version control, and 'psx check' reports those whose
+template has changed since. --update brings them up to date: a file you
+haven't edited is replaced, and one you have is merged with the new
+template, keeping your edits. Where your edits and the template change
+the same lines, both are written between <<<<<<< local and >>>>>>>
+template markers for you to resolve.
+
+Changes are planned first and written together at the end. If a write
+fails, the changes already made are rolled back and the project is left
+as it was.
+
+Once the changes are written, the rules that were fixed are checked again,
+and any fix that didn't make its rule pass is reported.
+
+Each run is recorded under .psx/history, with the original contents of the
+files it changed, so it can be reverted with 'psx undo'.
+
 Examples:
   psx fix                       # Interactive mode (asks before each fix)
   psx fix --dry-run             # Preview changes without applying
+  psx fix --dry-run --stat      # Summarize the preview per file
+  psx fix --all -o patch > psx.patch  # Export the fixes for review
+  psx fix --all --non-interactive --answer ci_platform=gitlab  # In CI
+  psx fix --all --branch psx/fixes --commit  # Fixes on a reviewable branch
   psx fix --rule readme         # Fix only README
+  psx fix --update --dry-run    # Preview updates of generated files
+  psx fix --rule package_metadata --dry-run  # Preview package.json edits
   psx fix --all                 # Fix all issues without prompts
-  psx fix --create-backups      # Create backups before modifying files`,
+  psx fix --create-backups      # Also keep <file>.bak copies of modified files
+  psx undo                      # Revert the last fix run`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runFixCommand,
 }
@@ -45,19 +105,69 @@ func init() {
 
 	FixCmd.Flags().BoolVar(&f.Fix.CreateBackups, "create-backups", df.CreateBackups,
 		"create backup files before modifying")
+
+	FixCmd.Flags().BoolVar(&f.Fix.Stat, "stat", df.Stat,
+		"with --dry-run, show a summary of changed lines per file instead of diffs")
+
+	FixCmd.Flags().BoolVar(&f.Fix.NoPager, "no-pager", df.NoPager,
+		"don't page --dry-run output")
+
+	FixCmd.Flags().StringArrayVar(&f.Fix.Answers, "answer", df.Answers,
+		"answer a question ahead of time, e.g. --answer ci_platform=gitlab (repeatable)")
+
+	FixCmd.Flags().StringVar(&f.Fix.AnswersFile, "answers-file", df.AnswersFile,
+		"YAML file of answers by question key")
+
+	FixCmd.Flags().BoolVar(&f.Fix.NonInteractive, "non-interactive", df.NonInteractive,
+		"never prompt: fail listing the questions without an answer")
+
+	FixCmd.Flags().StringVar(&f.Fix.Branch, "branch", df.Branch,
+		"create this git branch for the changes")
+
+	FixCmd.Flags().StringVar(&f.Fix.Commit, "commit", df.Commit,
+		"commit the changes: combined (one commit) or per-rule")
+	FixCmd.Flags().Lookup("commit").NoOptDefVal = commitCombined
+
+	FixCmd.Flags().BoolVar(&f.Fix.AllowDirty, "allow-dirty", df.AllowDirty,
+		"allow --branch and --commit with uncommitted changes")
+
+	FixCmd.Flags().BoolVar(&f.Fix.Update, "update", df.Update,
+		"update the files psx generated to the current templates, merging in local edits")
+
+	FixCmd.Flags().StringVarP(&f.Fix.Output, "output", "o", df.Output,
+		"output format: text | patch (print the planned changes as a git patch and change nothing)")
 }
 
 func runFixCommand(cmd *cobra.Command, args []string) error {
+	f := flags.GetFlags()
+
+	// a patch is a dry run whose changes go to stdout, and nothing else does
+	var patch io.Writer
+	switch f.Fix.Output {
+	case "text":
+	case "patch":
+		var restore func()
+		patch, restore = patchOutput()
+		defer restore()
+		f.Fix.DryRun = true
+	default:
+		return logger.Errorf("unknown output format '%s' - use text or patch", f.Fix.Output)
+	}
+
 	ctx, err := cmdctx.LoadProject(args)
 	if err != nil {
 		return err
 	}
+	gitRun, err := prepareGit(ctx.Path.Abs)
+	if err != nil {
+		return logger.Errorf("%v", err)
+	}
 
-	f := flags.GetFlags()
-
-	if f.Fix.DryRun {
+	if patch != nil {
+		logger.Info("Writing planned changes as a patch - no changes will be made")
+	} else if f.Fix.DryRun {
 		logger.Info(resources.GetMessage("fix", "dry_run"))
-	} else if f.Fix.Interactive {
+	} else if f.Fix.Interactive && !f.Fix.All && !f.Fix.NonInteractive && !f.Fix.Update {
 		logger.Info(resources.GetMessage("fix", "interactive"))
 	}
 	fmt.Println()
@@ -69,33 +179,54 @@ func runFixCommand(cmd *cobra.Command, args []string) error {
 		ProjectInfo: ctx.ProjectInfo,
 		Config:      ctx.Config,
 	}
+	if f.Fix.Update {
+		return updateGenerated(ctx, rulesCtx, patch, gitRun)
+	}
+
 	execResult, err := rules.Execute(ctx.Config, rulesCtx)
 	if err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
-	failedRules := getFixableRules(execResult)
+	// a single rule is fixed even when it passes, e.g. to complete an
+	// existing .gitignore
+	if f.Fix.RuleID != "" {
+		return fixSpecificRule(ctx, rulesCtx, f.Fix.RuleID, patch, gitRun)
+	}
+
+	failedRules := withMergeFixes(getFixableRules(execResult, ""), ctx.Config, rulesCtx)
+	fixableCount := countFixable(execResult) + countMerges(ctx.Config, rulesCtx)
 
-	if len(failedRules) == 0 {
+	if fixableCount == 0 {
 		logger.Success(resources.GetMessage("fix", "success_none"))
 		return nil
 	}
 
-	logger.Info(resources.FormatMessage("fix", "prompt_many", len(failedRules)))
+	logger.Info(resources.FormatMessage("fix", "prompt_many", fixableCount))
 	fmt.Println()
-	if f.Fix.RuleID != "" {
-		return fixSpecificRule(ctx, rulesCtx, f.Fix.RuleID)
-	}
-	fixCtx := &rules.FixContext{
-		Context:       rulesCtx,
-		Interactive:   f.Fix.Interactive && !f.Fix.All,
-		DryRun:        f.Fix.DryRun,
-		CreateBackups: f.Fix.CreateBackups,
+	fixCtx := newFixContext(ctx, rulesCtx, f.Fix.Interactive && !f.Fix.All)
+	defer closeJournal(fixCtx)
+
+	results := []*rules.FixResult{}
+	if len(failedRules) > 0 {
+		results, err = rules.FixAll(ctx.Config, fixCtx, failedRules)
+		if err != nil {
+			return fmt.Errorf("fix failed: %w", err)
+		}
 	}
-
-	results, err := rules.FixAll(ctx.Config, fixCtx, failedRules)
+	scopeResults, err := fixScopes(ctx.Config.Scopes, execResult, fixCtx)
+	results = append(results, scopeResults...)
 	if err != nil {
 		return fmt.Errorf("fix failed: %w", err)
 	}
+	if err := applyFixes(fixCtx, gitRun); err != nil {
+		return err
+	}
+	if patch != nil {
+		return printPatch(patch, ctx.Path.Abs, results)
+	}
+	if f.Fix.DryRun && !f.Fix.NoPager {
+		defer utils.Page()()
+	}
 	displayFixResults(results, f.Fix.DryRun)
 	summary := generateSummary(results)
 	displayFixSummary(summary, f.Fix.DryRun)
@@ -109,26 +240,31 @@ func runFixCommand(cmd *cobra.Command, args []string) error {
 	if summary.Fixed > 0 {
 		fmt.Println()
 		logger.Success(resources.FormatMessage("fix", "success_many", summary.Fixed))
-		logger.Info("Run 'psx check' to verify")
+		verifyFixes(ctx, rulesCtx, results)
 	}
 
+	if err := gitRun.finish(results); err != nil {
+		return logger.Errorf("commit failed: %v", err)
+	}
 	return nil
 }
 
-func fixSpecificRule(ctx *cmdctx.ProjectContext, rulesCtx *rules.Context, ruleID string) error {
+func fixSpecificRule(ctx *cmdctx.ProjectContext, rulesCtx *rules.Context, ruleID string, patch io.Writer, gitRun *fixGit) error {
 	f := flags.GetFlags()
 
-	fixCtx := &rules.FixContext{
-		Context:       rulesCtx,
-		Interactive:   f.Fix.Interactive,
-		DryRun:        f.Fix.DryRun,
-		CreateBackups: f.Fix.CreateBackups,
-	}
+	fixCtx := newFixContext(ctx, rulesCtx, f.Fix.Interactive)
+	defer closeJournal(fixCtx)
 
 	result, err := rules.Fix(ctx.Config, fixCtx, ruleID)
 	if err != nil {
 		return fmt.Errorf("fix failed: %w", err)
 	}
+	if err := applyFixes(fixCtx, gitRun); err != nil {
+		return err
+	}
+	if patch != nil {
+		return printPatch(patch, ctx.Path.Abs, []*rules.FixResult{result})
+	}
 
 	if result.Skipped {
 		logger.Info("Fix skipped")
@@ -136,8 +272,15 @@ func fixSpecificRule(ctx *cmdctx.ProjectContext, rulesCtx *rules.Context, ruleID
 	}
 
 	if result.Fixed {
-		for _, change := range result.Changes {
-			printChange(change, f.Fix.DryRun)
+		if f.Fix.DryRun && !f.Fix.NoPager {
+			defer utils.Page()()
+		}
+		if f.Fix.DryRun && f.Fix.Stat {
+			printStat(result.Changes)
+		} else {
+			for _, change := range result.Changes {
+				printChange(change, f.Fix.DryRun)
+			}
 		}
 
 		if f.Fix.DryRun {
@@ -146,17 +289,202 @@ func fixSpecificRule(ctx *cmdctx.ProjectContext, rulesCtx *rules.Context, ruleID
 		} else {
 			fmt.Println()
 			logger.Success(resources.GetMessage("fix", "success_one"))
+			verifyFixes(ctx, rulesCtx, []*rules.FixResult{result})
 		}
 	}
 
+	if err := gitRun.finish([]*rules.FixResult{result}); err != nil {
+		return logger.Errorf("commit failed: %v", err)
+	}
 	return nil
 }
 
-func getFixableRules(result *rules.ExecutionResult) []string {
+// updateGenerated brings the files psx generated up to date with their
+// templates, for --update, instead of fixing failed rules
+func updateGenerated(ctx *cmdctx.ProjectContext, rulesCtx *rules.Context, patch io.Writer, gitRun *fixGit) error {
+	f := flags.GetFlags()
+
+	fixCtx := newFixContext(ctx, rulesCtx, false)
+	defer closeJournal(fixCtx)
+
+	results, err := rules.Update(fixCtx, f.Fix.RuleID)
+	if err != nil {
+		return logger.Errorf("update failed: %v", err)
+	}
+	if err := applyFixes(fixCtx, gitRun); err != nil {
+		return err
+	}
+	if patch != nil {
+		return printPatch(patch, ctx.Path.Abs, results)
+	}
+	if len(results) == 0 {
+		logger.Success("Generated files are up to date with their templates")
+		return nil
+	}
+
+	if f.Fix.DryRun && !f.Fix.NoPager {
+		defer utils.Page()()
+	}
+	displayFixResults(results, f.Fix.DryRun)
+	summary := generateSummary(results)
+	displayFixSummary(summary, f.Fix.DryRun)
+
+	if f.Fix.DryRun {
+		fmt.Println()
+		logger.Info("Run without --dry-run to apply changes")
+		return nil
+	}
+	for _, result := range results {
+		for _, change := range result.Changes {
+			if strings.Contains(change.Content, utils.ConflictLocal) {
+				logger.Warning(fmt.Sprintf("%s has conflicts - keep what you need between the %s and %s markers",
+					changeName(change.Path), utils.ConflictLocal, utils.ConflictTemplate))
+			}
+		}
+	}
+
+	if err := gitRun.finish(results); err != nil {
+		return logger.Errorf("commit failed: %v", err)
+	}
+	return nil
+}
+
+// verifyFixes checks the fixed rules again and points out those the fix
+// didn't satisfy
+func verifyFixes(ctx *cmdctx.ProjectContext, rulesCtx *rules.Context, results []*rules.FixResult) {
+	failing := rules.Verify(ctx.Config, rulesCtx, results)
+	if len(failing) == 0 {
+		logger.Success(resources.GetMessage("fix", "verified"))
+		return
+	}
+	for _, result := range failing {
+		name := result.RuleID
+		if result.Scope != "" {
+			name = fmt.Sprintf("%s (%s/)", name, result.Scope)
+		}
+		logger.Warning(resources.FormatMessage("fix", "still_failing", name, result.Message))
+	}
+}
+
+// applyFixes applies the plan of a run, on its own branch with --branch.
+// When applying fails, the changes are rolled back and the branch removed
+func applyFixes(fixCtx *rules.FixContext, gitRun *fixGit) error {
+	if err := checkAnswered(); err != nil {
+		return err
+	}
+	if fixCtx.Plan.Empty() {
+		return nil
+	}
+	if err := gitRun.start(); err != nil {
+		return logger.Errorf("%v", err)
+	}
+	if err := fixCtx.Plan.Apply(fixCtx.Journal); err != nil {
+		gitRun.abort()
+		return logger.Errorf("fix failed: %v", err)
+	}
+	return nil
+}
+
+// checkAnswered fails a --non-interactive run that came across questions
+// without an answer. It runs once everything is planned and before anything
+// is written, so it lists all of them
+func checkAnswered() error {
+	keys := utils.Unanswered()
+	if len(keys) == 0 {
+		return nil
+	}
+	return logger.Errorf("no answer for %s - set them with --answer key=value, --answers-file or answers: in psx.yml",
+		strings.Join(keys, ", "))
+}
+
+// printPatch writes the planned changes of results as a patch to w
+func printPatch(w io.Writer, root string, results []*rules.FixResult) error {
+	files, err := writePatch(w, root, results)
+	if err != nil {
+		return logger.Errorf("failed to write patch: %v", err)
+	}
+	if files == 0 {
+		logger.Success(resources.GetMessage("fix", "success_none"))
+		return nil
+	}
+	logger.Success(fmt.Sprintf("Wrote a patch changing %d files - apply it with 'git apply'", files))
+	return nil
+}
+
+// newFixContext sets up a fix run. --non-interactive never asks before a
+// fix, like --all. Fixes only plan their writes, which are
+// applied together once every rule is done; a run that changes files is
+// journaled under .psx/history so 'psx undo' can revert it
+func newFixContext(ctx *cmdctx.ProjectContext, rulesCtx *rules.Context, interactive bool) *rules.FixContext {
+	f := flags.GetFlags()
+	backups := f.Fix.CreateBackups || ctx.Config.Fix.Backup
+
+	fixCtx := &rules.FixContext{
+		Context:       rulesCtx,
+		Interactive:   interactive && !f.Fix.NonInteractive,
+		DryRun:        f.Fix.DryRun,
+		CreateBackups: backups,
+	}
+	if !f.Fix.DryRun {
+		command := "psx " + strings.Join(os.Args[1:], " ")
+		fixCtx.Plan = rules.NewPlan(ctx.Path.Abs)
+		fixCtx.Journal = history.Begin(ctx.Path.Abs, command, backups)
+	}
+	return fixCtx
+}
+
+// closeJournal writes the journal of a fix run and says how to undo it
+func closeJournal(fixCtx *rules.FixContext) {
+	run, err := fixCtx.Journal.Close()
+	if err != nil {
+		logger.Warning(fmt.Sprintf("Failed to record fix history: %v", err))
+		return
+	}
+	if run != nil {
+		logger.Info(fmt.Sprintf("Undo with 'psx undo %s'", run.ID))
+	}
+}
+
+// fixScopes fixes the failing rules of nested config scopes, each relative
+// to its own directory
+func fixScopes(scopes []*config.Config, execResult *rules.ExecutionResult, fixCtx *rules.FixContext) ([]*rules.FixResult, error) {
+	results := []*rules.FixResult{}
+
+	for _, scope := range scopes {
+		scopeFixCtx := *fixCtx
+		scopeFixCtx.Context = rules.ScopeContext(fixCtx.Context, scope)
+		failed := withMergeFixes(getFixableRules(execResult, scope.Scope), scope, scopeFixCtx.Context)
+		if len(failed) > 0 {
+
+			scopeResults, err := rules.FixAll(scope, &scopeFixCtx, failed)
+			for _, result := range scopeResults {
+				for i := range result.Changes {
+					result.Changes[i].Description = fmt.Sprintf("%s (%s/)", result.Changes[i].Description, scope.Scope)
+				}
+			}
+			results = append(results, scopeResults...)
+			if err != nil {
+				return results, err
+			}
+		}
+
+		nested, err := fixScopes(scope.Scopes, execResult, fixCtx)
+		results = append(results, nested...)
+		if err != nil {
+			return results, err
+		}
+	}
+
+	return results, nil
+}
+
+func getFixableRules(result *rules.ExecutionResult, scope string) []string {
 	fixable := []string{}
+	seen := map[string]bool{}
 
 	for _, r := range result.Results {
-		if !r.Passed {
+		if !r.Passed && !r.Suppressed && r.Scope == scope && !seen[r.RuleID] {
+			seen[r.RuleID] = true
 			fixable = append(fixable, r.RuleID)
 		}
 	}
@@ -164,7 +492,40 @@ func getFixableRules(result *rules.ExecutionResult) []string {
 	return fixable
 }
 
+// withMergeFixes adds the rules whose existing file can be completed from
+// the template, which pass the check and so aren't in failed
+func withMergeFixes(failed []string, cfg *config.Config, ctx *rules.Context) []string {
+	for _, id := range rules.MergeableRules(cfg, ctx) {
+		if !slices.Contains(failed, id) {
+			failed = append(failed, id)
+		}
+	}
+	return failed
+}
+
+// countMerges counts the mergeable files of the project and its scopes
+func countMerges(cfg *config.Config, ctx *rules.Context) int {
+	count := len(rules.MergeableRules(cfg, ctx))
+	for _, scope := range cfg.Scopes {
+		count += countMerges(scope, rules.ScopeContext(ctx, scope))
+	}
+	return count
+}
+
+func countFixable(result *rules.ExecutionResult) int {
+	seen := map[string]bool{}
+	for _, r := range result.Results {
+		if !r.Passed && !r.Suppressed {
+			seen[r.Scope+"\x00"+r.RuleID] = true
+		}
+	}
+	return len(seen)
+}
+
 func displayFixResults(results []*rules.FixResult, dryRun bool) {
+	f := flags.GetFlags()
+	stat := []rules.Change{}
+
 	for _, fix := range results {
 		if fix.Skipped {
 			logger.Verbose(fmt.Sprintf("Skipped: %s", fix.RuleID))
@@ -178,11 +539,19 @@ func displayFixResults(results []*rules.FixResult, dryRun bool) {
 
 		if fix.Fixed {
 			logger.Verbose(resources.FormatMessage("fix", "applied", fix.RuleID))
+			if dryRun && f.Fix.Stat {
+				stat = append(stat, fix.Changes...)
+				continue
+			}
 			for _, change := range fix.Changes {
 				printChange(change, dryRun)
 			}
 		}
 	}
+
+	if len(stat) > 0 {
+		printStat(stat)
+	}
 }
 
 func printChange(change rules.Change, dryRun bool) {
@@ -199,11 +568,88 @@ func printChange(change rules.Change, dryRun bool) {
 
 	fmt.Printf("%s %s\n", prefix, change.Description)
 
+	if dryRun {
+		fmt.Print(utils.ColorDiff(changeDiff(change)))
+		return
+	}
+
 	if f.GlobalFlags.Verbose && change.Content != "" {
 		fmt.Println(formatContent(change.Content, 5))
 	}
 }
 
+// changeDiff is the unified diff of a planned change, "" for folders
+func changeDiff(change rules.Change) string {
+	name := changeName(change.Path)
+	switch change.Type {
+	case rules.ChangeCreateFile:
+		return utils.UnifiedDiff("/dev/null", "b/"+name, change.Original, change.Content)
+	case rules.ChangeModifyFile:
+		return utils.UnifiedDiff("a/"+name, "b/"+name, change.Original, change.Content)
+	case rules.ChangeDeleteFile:
+		return utils.UnifiedDiff("a/"+name, "/dev/null", change.Original, "")
+	}
+	return ""
+}
+
+// printStat shows planned changes like git diff --stat: changed lines per
+// file with a bar of +/-, then the totals
+func printStat(changes []rules.Change) {
+	const barWidth = 40
+
+	if flags.GetFlags().GlobalFlags.Quiet {
+		return
+	}
+
+	type stat struct {
+		name           string
+		added, removed int
+	}
+	stats := make([]stat, 0, len(changes))
+	width, most := 0, 0
+	for _, change := range changes {
+		s := stat{name: changeName(change.Path)}
+		if change.Type == rules.ChangeCreateFolder {
+			s.name += "/"
+		} else {
+			s.added, s.removed = utils.DiffStat(change.Original, change.Content)
+		}
+		stats = append(stats, s)
+		width = max(width, len(s.name))
+		most = max(most, s.added+s.removed)
+	}
+
+	files, added, removed := 0, 0, 0
+	for _, s := range stats {
+		if strings.HasSuffix(s.name, "/") {
+			fmt.Printf(" %-*s | new folder\n", width, s.name)
+			continue
+		}
+		plus, minus := s.added, s.removed
+		if most > barWidth {
+			plus = (s.added*barWidth + most - 1) / most
+			minus = (s.removed*barWidth + most - 1) / most
+		}
+		fmt.Printf(" %-*s | %4d %s%s\n", width, s.name, s.added+s.removed,
+			color.GreenString("%s", strings.Repeat("+", plus)),
+			color.RedString("%s", strings.Repeat("-", minus)))
+		files++
+		added += s.added
+		removed += s.removed
+	}
+	fmt.Printf(" %d files changed, %d insertions(+), %d deletions(-)\n", files, added, removed)
+}
+
+// changeName shows a changed file relative to the working directory
+func changeName(path string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return path
+}
+
 type FixSummary struct {
 	Total   int
 	Fixed   int
@@ -0,0 +1,224 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/m-mdy-m/psx/internal/flags"
+	"github.com/m-mdy-m/psx/internal/git"
+	"github.com/m-mdy-m/psx/internal/history"
+	"github.com/m-mdy-m/psx/internal/logger"
+	"github.com/m-mdy-m/psx/internal/managed"
+	"github.com/m-mdy-m/psx/internal/rules"
+)
+
+const (
+	commitCombined = "combined"
+	commitPerRule  = "per-rule"
+)
+
+// fixGit puts the changes of a fix run on a branch and commits them, for
+// --branch and --commit
+type fixGit struct {
+	repo     *git.Repo
+	root     string // project root
+	branch   string // created for the run, "" to stay on the current one
+	commit   string // "" for no commits
+	previous string // what was checked out before the branch
+	detached bool
+}
+
+// prepareGit checks what --branch and --commit need before anything is
+// planned: a repository, a git identity to commit as, and a clean work
+// tree unless --allow-dirty. It returns nil when neither flag is set
+func prepareGit(root string) (*fixGit, error) {
+	f := flags.GetFlags()
+	if f.Fix.Branch == "" && f.Fix.Commit == "" {
+		return nil, nil
+	}
+
+	switch f.Fix.Commit {
+	case "", commitCombined, commitPerRule:
+	default:
+		return nil, fmt.Errorf("unknown --commit mode '%s' - use %s or %s", f.Fix.Commit, commitCombined, commitPerRule)
+	}
+	if f.Fix.DryRun {
+		return nil, fmt.Errorf("--branch and --commit can't be used with --dry-run or --output patch")
+	}
+
+	repo, err := git.Open(root)
+	if err != nil {
+		return nil, fmt.Errorf("--branch and --commit: %w", err)
+	}
+	if f.Fix.Commit != "" {
+		if _, _, err := repo.Identity(); err != nil {
+			return nil, err
+		}
+	}
+
+	if !f.Fix.AllowDirty {
+		dirty, err := dirtyPaths(repo, root)
+		if err != nil {
+			return nil, err
+		}
+		if len(dirty) > 0 {
+			return nil, fmt.Errorf("the working tree has uncommitted changes (%s) - commit or stash them, or use --allow-dirty",
+				strings.Join(dirty, ", "))
+		}
+	}
+
+	return &fixGit{repo: repo, root: root, branch: f.Fix.Branch, commit: f.Fix.Commit}, nil
+}
+
+// dirtyPaths lists uncommitted changes, leaving out the files psx keeps for
+// itself in the project and never commits
+func dirtyPaths(repo *git.Repo, root string) ([]string, error) {
+	changes, err := repo.Changes()
+	if err != nil {
+		return nil, err
+	}
+
+	dirty := []string{}
+	for _, path := range changes {
+		rel, err := filepath.Rel(root, filepath.Join(repo.Root, path))
+		if err == nil {
+			rel = filepath.ToSlash(rel)
+			if rel == ".psx-project.yml" || strings.HasPrefix(rel, history.Dir+"/") || strings.HasPrefix(rel, history.StagingDir+"/") {
+				continue
+			}
+		}
+		dirty = append(dirty, path)
+	}
+	return dirty, nil
+}
+
+// start creates the branch, right before the plan is applied
+func (g *fixGit) start() error {
+	if g == nil || g.branch == "" {
+		return nil
+	}
+	previous, detached, err := g.repo.Head()
+	if err != nil {
+		return err
+	}
+	if err := g.repo.CreateBranch(g.branch); err != nil {
+		return err
+	}
+	g.previous, g.detached = previous, detached
+	logger.Info(fmt.Sprintf("Switched to new branch '%s'", g.branch))
+	return nil
+}
+
+// abort goes back to where the run started and removes its branch, after
+// the plan failed to apply
+func (g *fixGit) abort() {
+	if g == nil || g.previous == "" {
+		return
+	}
+	if err := g.repo.Switch(g.previous, g.detached); err != nil {
+		logger.Warning(fmt.Sprintf("Failed to switch back to %s: %v", g.previous, err))
+		return
+	}
+	if err := g.repo.DeleteBranch(g.branch); err != nil {
+		logger.Warning(fmt.Sprintf("Failed to delete branch %s: %v", g.branch, err))
+	}
+}
+
+// finish commits the applied changes: one commit, or one per rule
+func (g *fixGit) finish(results []*rules.FixResult) error {
+	if g == nil || g.commit == "" {
+		return nil
+	}
+
+	fixed := []*rules.FixResult{}
+	for _, result := range results {
+		if result.Fixed && result.Error == nil && len(result.Changes) > 0 {
+			fixed = append(fixed, result)
+		}
+	}
+
+	groups := [][]*rules.FixResult{fixed}
+	if g.commit == commitPerRule {
+		groups = groups[:0]
+		for _, result := range fixed {
+			groups = append(groups, []*rules.FixResult{result})
+		}
+	}
+
+	for i, group := range groups {
+		paths := changedPaths(group)
+		// the manifest goes in with the last commit, once it lists the
+		// files of every rule
+		if i == len(groups)-1 {
+			paths = append(paths, g.managedPaths()...)
+		}
+		hash, err := g.repo.Commit(commitMessage(group), paths)
+		if err != nil {
+			return err
+		}
+		if hash != "" {
+			logger.Success(fmt.Sprintf("Committed %s: %s", hash, commitSubject(group)))
+		}
+	}
+	return nil
+}
+
+// managedPaths are the record of generated files and the content they
+// were generated with, when the project has them
+func (g *fixGit) managedPaths() []string {
+	paths := []string{}
+	for _, rel := range []string{managed.File, managed.BaseDir} {
+		path := filepath.Join(g.root, filepath.FromSlash(rel))
+		if _, err := os.Stat(path); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// commitMessage describes the changes of results: a subject naming the
+// rules, then each change under its rule
+func commitMessage(results []*rules.FixResult) string {
+	var b strings.Builder
+	b.WriteString(commitSubject(results) + "\n")
+	for _, result := range results {
+		b.WriteString("\n")
+		if len(results) > 1 {
+			b.WriteString(result.RuleID + ":\n")
+		}
+		for _, change := range result.Changes {
+			b.WriteString("- " + change.Description + "\n")
+		}
+	}
+	return b.String()
+}
+
+func commitSubject(results []*rules.FixResult) string {
+	switch len(results) {
+	case 0:
+		return "psx fix"
+	case 1, 2, 3:
+		ids := make([]string, len(results))
+		for i, result := range results {
+			ids[i] = result.RuleID
+		}
+		return "psx fix: " + strings.Join(ids, ", ")
+	}
+	return fmt.Sprintf("psx fix: %d rules", len(results))
+}
+
+// changedPaths are the files the changes of results touched; folders are
+// left to the files in them, as git doesn't track empty folders
+func changedPaths(results []*rules.FixResult) []string {
+	paths := []string{}
+	for _, result := range results {
+		for _, change := range result.Changes {
+			if change.Type != rules.ChangeCreateFolder {
+				paths = append(paths, change.Path)
+			}
+		}
+	}
+	return paths
+}
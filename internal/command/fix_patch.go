@@ -0,0 +1,99 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/m-mdy-m/psx/internal/rules"
+	"github.com/m-mdy-m/psx/internal/utils"
+)
+
+// patchOutput moves everything psx prints to stderr, so prompts and
+// messages don't end up in a redirected patch, and returns the real stdout
+func patchOutput() (io.Writer, func()) {
+	stdout, output := os.Stdout, color.Output
+	os.Stdout, color.Output = os.Stderr, os.Stderr
+	return stdout, func() {
+		os.Stdout, color.Output = stdout, output
+	}
+}
+
+// writePatch writes the planned changes as a git patch relative to root,
+// which `git apply` accepts. Folders are left out: git has no empty
+// folders, and the files in them create them anyway
+func writePatch(w io.Writer, root string, results []*rules.FixResult) (int, error) {
+	files := 0
+	seen := map[string]bool{}
+
+	for _, result := range results {
+		if !result.Fixed || result.Error != nil {
+			continue
+		}
+		for _, change := range result.Changes {
+			if change.Type == rules.ChangeCreateFolder || seen[change.Path] {
+				continue
+			}
+			seen[change.Path] = true
+
+			patch := filePatch(root, change)
+			if patch == "" {
+				continue
+			}
+			if _, err := io.WriteString(w, patch); err != nil {
+				return files, err
+			}
+			files++
+		}
+	}
+	return files, nil
+}
+
+// filePatch is the git diff of one planned change, "" when it changes
+// nothing
+func filePatch(root string, change rules.Change) string {
+	name := change.Path
+	if rel, err := filepath.Rel(root, change.Path); err == nil && !strings.HasPrefix(rel, "..") {
+		name = rel
+	}
+	name = filepath.ToSlash(name)
+	header := fmt.Sprintf("diff --git a/%s b/%s\n", name, name)
+
+	// an empty file psx fills in is an edit, not a new file
+	if _, err := os.Stat(change.Path); err == nil && change.Type == rules.ChangeCreateFile {
+		change.Type = rules.ChangeModifyFile
+	}
+
+	switch change.Type {
+	case rules.ChangeCreateFile:
+		mode := utils.NewFileMode([]byte(change.Content))
+		return header + fmt.Sprintf("new file mode %s\n", gitMode(mode)) +
+			utils.UnifiedDiff("/dev/null", "b/"+name, "", change.Content)
+	case rules.ChangeDeleteFile:
+		mode := os.FileMode(0644)
+		if info, err := os.Stat(change.Path); err == nil {
+			mode = info.Mode()
+		}
+		return header + fmt.Sprintf("deleted file mode %s\n", gitMode(mode)) +
+			utils.UnifiedDiff("a/"+name, "/dev/null", change.Original, "")
+	case rules.ChangeModifyFile:
+		diff := utils.UnifiedDiff("a/"+name, "b/"+name, change.Original, change.Content)
+		if diff == "" {
+			return ""
+		}
+		return header + diff
+	}
+	return ""
+}
+
+// gitMode is the mode git records for a file: executable or not
+func gitMode(mode os.FileMode) string {
+	if mode&0111 != 0 {
+		return "100755"
+	}
+	return "100644"
+}
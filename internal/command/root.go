@@ -49,6 +49,10 @@ func init() {
 	initGlobalFlags()
 	rootCmd.AddCommand(CheckCmd)
 	rootCmd.AddCommand(FixCmd)
+	rootCmd.AddCommand(ConfigCmd)
+	rootCmd.AddCommand(UndoCmd)
+	rootCmd.AddCommand(HistoryCmd)
+	rootCmd.AddCommand(SelfTestCmd)
 }
 
 func initGlobalFlags() {
@@ -66,6 +70,12 @@ func initGlobalFlags() {
 
 	rootCmd.PersistentFlags().BoolVar(&f.GlobalFlags.NoColor, "no-color", df.NoColor,
 		"disable colors")
+
+	rootCmd.PersistentFlags().StringVar(&f.GlobalFlags.Profile, "profile", df.Profile,
+		"config profile to use (default: ci on CI, local otherwise, if defined)")
+
+	rootCmd.PersistentFlags().StringArrayVar(&f.GlobalFlags.Set, "set", df.Set,
+		"override a config setting, e.g. --set rules.changelog=error (repeatable)")
 }
 
 func preRun(cmd *cobra.Command, args []string) {
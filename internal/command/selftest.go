@@ -0,0 +1,99 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/m-mdy-m/psx/internal/flags"
+	"github.com/m-mdy-m/psx/internal/logger"
+	"github.com/m-mdy-m/psx/internal/resources"
+	"github.com/m-mdy-m/psx/internal/rules"
+	"github.com/m-mdy-m/psx/internal/utils"
+)
+
+var SelfTestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Check that the fix of every rule makes the rule pass",
+	Long: `Run the fix of every built-in rule in an empty project of each project
+type, each in its own temporary directory, and check the rule again. A fix
+that doesn't make its rule pass, e.g. because it writes a file the rule's
+patterns don't match, is reported with what it wrote.
+
+Nothing in the current directory is read or changed. Questions are
+answered with their defaults and placeholder project info is used.
+
+Examples:
+  psx selftest                  # All project types
+  psx selftest --type go        # Only Go projects
+  psx selftest -v               # Also list the rules that pass`,
+	Args: cobra.NoArgs,
+	RunE: runSelfTestCommand,
+}
+
+func init() {
+	f := flags.GetFlags()
+	df := flags.DefaultValues.SelfTest
+
+	SelfTestCmd.Flags().StringArrayVar(&f.SelfTest.Types, "type", df.Types,
+		"project type to test (repeatable, default: all)")
+}
+
+func runSelfTestCommand(cmd *cobra.Command, args []string) error {
+	f := flags.GetFlags()
+
+	known := append([]string{"generic"}, resources.ProjectTypes()...)
+	types := known
+	if len(f.SelfTest.Types) > 0 {
+		types = []string{}
+		for _, t := range f.SelfTest.Types {
+			t = resources.NormalizeProjectType(t)
+			if !slices.Contains(known, t) {
+				return logger.Errorf("unknown project type '%s' - use %s", t, strings.Join(known, ", "))
+			}
+			types = append(types, t)
+		}
+	}
+
+	// fixes run unattended, with the default answer to every question
+	utils.SetNonInteractive(true)
+	info := resources.DefaultProjectInfo()
+
+	results := []rules.SelfTestResult{}
+	for _, projectType := range types {
+		logger.Verbose(fmt.Sprintf("Testing fixes in a %s project", projectType))
+		typeResults, err := rules.SelfTest(projectType, info)
+		results = append(results, typeResults...)
+		if err != nil {
+			return logger.Errorf("selftest failed for %s: %v", projectType, err)
+		}
+	}
+
+	failed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tRULE\tRESULT\tDETAIL")
+	for _, result := range results {
+		if result.Failed() {
+			failed++
+		} else if !f.GlobalFlags.Verbose {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.ProjectType, result.RuleID, result.Status, result.Detail)
+	}
+	if failed > 0 || f.GlobalFlags.Verbose {
+		w.Flush()
+		fmt.Println()
+	}
+
+	if failed > 0 {
+		logger.Error(fmt.Sprintf("%d of %d fixes don't make their rule pass", failed, len(results)))
+		os.Exit(utils.ExitFailed)
+	}
+	logger.Success(fmt.Sprintf("Every fix makes its rule pass (%d rules, %d project types)",
+		len(results)/len(types), len(types)))
+	return nil
+}
@@ -0,0 +1,147 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/m-mdy-m/psx/internal/cmdctx"
+	"github.com/m-mdy-m/psx/internal/flags"
+	"github.com/m-mdy-m/psx/internal/history"
+	"github.com/m-mdy-m/psx/internal/logger"
+)
+
+var UndoCmd = &cobra.Command{
+	Use:   "undo [run-id]",
+	Short: "Revert a fix run",
+	Long: `Revert what a 'psx fix' run changed: modified files get their original
+contents back, created files and folders are removed. Without a run ID the
+last run that hasn't been undone is reverted.
+
+Files changed since the run are left alone unless --force is given. Run
+from the project directory; the history is kept in .psx/history.
+
+Examples:
+  psx undo                      # Revert the last fix run
+  psx undo 20261019-142301      # Revert a specific run
+  psx undo --dry-run            # Show what would be reverted
+  psx history fixes             # List runs`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runUndoCommand,
+}
+
+var HistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show what psx has changed in the project",
+}
+
+var historyFixesCmd = &cobra.Command{
+	Use:   "fixes",
+	Short: "List fix runs that can be undone",
+	Args:  cobra.NoArgs,
+	RunE:  runHistoryFixesCommand,
+}
+
+func init() {
+	f := flags.GetFlags()
+	df := flags.DefaultValues.Undo
+
+	UndoCmd.Flags().BoolVar(&f.Undo.DryRun, "dry-run", df.DryRun,
+		"show what would be reverted without changing anything")
+	UndoCmd.Flags().BoolVar(&f.Undo.Force, "force", df.Force,
+		"revert files even if they changed since the run")
+
+	HistoryCmd.AddCommand(historyFixesCmd)
+}
+
+func runUndoCommand(cmd *cobra.Command, args []string) error {
+	pathCtx, err := cmdctx.ResolvePath(nil)
+	if err != nil {
+		return err
+	}
+	root := pathCtx.Abs
+
+	var run *history.Run
+	if len(args) == 1 {
+		run, err = history.Find(root, args[0])
+	} else {
+		run, err = history.Latest(root)
+	}
+	if errors.Is(err, history.ErrNoRuns) {
+		logger.Info(fmt.Sprintf("No fix runs to undo in %s", root))
+		return nil
+	}
+	if err != nil {
+		return logger.Errorf("%v", err)
+	}
+
+	undo, err := history.PlanUndo(root, run)
+	if err != nil {
+		return logger.Errorf("%v", err)
+	}
+
+	f := flags.GetFlags()
+	prefix := "✓"
+	if f.Undo.DryRun {
+		prefix = "→"
+	}
+	logger.Info(fmt.Sprintf("Run %s: %s", run.ID, run.Command))
+	if !f.Undo.DryRun {
+		if err := undo.Apply(root, f.Undo.Force); err != nil {
+			return logger.Errorf("%v", err)
+		}
+	}
+	for _, path := range undo.Restored {
+		fmt.Printf("%s Restore %s\n", prefix, path)
+	}
+	for _, path := range undo.Removed {
+		fmt.Printf("%s Remove %s\n", prefix, path)
+	}
+	for _, path := range undo.Kept {
+		logger.Verbose(fmt.Sprintf("Keeping %s: it has files the run didn't create", path))
+	}
+	for _, path := range undo.Conflicts {
+		logger.Warning(fmt.Sprintf("%s changed since the run", path))
+	}
+
+	if f.Undo.DryRun {
+		fmt.Println()
+		logger.Info("Run without --dry-run to revert")
+		return nil
+	}
+	logger.Success(fmt.Sprintf("Reverted run %s", run.ID))
+	return nil
+}
+
+func runHistoryFixesCommand(cmd *cobra.Command, args []string) error {
+	pathCtx, err := cmdctx.ResolvePath(nil)
+	if err != nil {
+		return err
+	}
+
+	runs, err := history.Runs(pathCtx.Abs)
+	if err != nil {
+		return logger.Errorf("%v", err)
+	}
+	if len(runs) == 0 {
+		logger.Info("No fix runs recorded")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RUN\tTIME\tCREATED\tMODIFIED\tSTATUS\tCOMMAND")
+	for i := len(runs) - 1; i >= 0; i-- {
+		run := runs[i]
+		status := "applied"
+		if run.Undone != nil {
+			status = "undone"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%s\n", run.ID, run.Time.Local().Format(time.DateTime),
+			len(run.Created), len(run.Modified), status, run.Command)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+
+	"github.com/m-mdy-m/psx/internal/utils"
+)
+
+// DefaultConfigFile is created by edits when a project has no config yet
+const DefaultConfigFile = "psx.yml"
+
+// Edit is a change to one config file. Edits work on the YAML AST, so
+// comments, key order and flow/block style are kept
+type Edit struct {
+	File    string
+	Created bool // the file doesn't exist yet
+	Before  []byte
+	After   []byte
+
+	source string // the content the AST was parsed from
+	file   *ast.File
+}
+
+// Changed reports whether the edit changes the file
+func (e *Edit) Changed() bool {
+	return string(e.Before) != string(e.After)
+}
+
+// EditFile starts an edit of the YAML config at path. A missing file is
+// started from an empty config at the current version
+func EditFile(path string) (*Edit, error) {
+	if configFormat(path) != FormatYAML {
+		return nil, fmt.Errorf("%s: only YAML config files can be edited - change it by hand", path)
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	edit := &Edit{File: path}
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		edit.Created = true
+		data = []byte(fmt.Sprintf("version: %d\n", CurrentVersion))
+	case err != nil:
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	default:
+		edit.Before = data
+	}
+
+	edit.file, err = parser.ParseBytes(data, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to parse YAML: %w", path, err)
+	}
+	if len(edit.file.Docs) == 0 || edit.file.Docs[0].Body == nil {
+		// comments only; start the settings below them
+		data = append(data, []byte(fmt.Sprintf("version: %d\n", CurrentVersion))...)
+		edit.file, err = parser.ParseBytes(data, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+	}
+	edit.source = string(data)
+	edit.After = edit.render()
+	return edit, nil
+}
+
+// Set sets the dotted key (e.g. "rules.changelog") to value, adding any
+// missing parent keys
+func (e *Edit) Set(key string, value any) error {
+	segments, err := editSegments(key)
+	if err != nil {
+		return err
+	}
+
+	// find the deepest part of the key the file already has
+	depth := len(segments)
+	for depth > 0 && e.node(segments[:depth]) == nil {
+		depth--
+	}
+
+	switch {
+	case depth == len(segments):
+		node, err := yaml.ValueToNode(value)
+		if err != nil {
+			return err
+		}
+		err = editPath(segments).ReplaceWithNode(e.file, node)
+		if err != nil {
+			return fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	default:
+		node, err := yaml.ValueToNode(nestedValue(segments[depth:], value))
+		if err != nil {
+			return err
+		}
+		parent := editPath(segments[:depth])
+		switch e.node(segments[:depth]).Type() {
+		case ast.MappingType, ast.MappingValueType:
+			err = parent.MergeFromNode(e.file, node)
+		default:
+			// e.g. rules.changelog.severity when rules.changelog is a scalar
+			err = parent.ReplaceWithNode(e.file, node)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+
+	e.After = e.render()
+	return nil
+}
+
+// Append adds values to the list at key, skipping ones it already has. A
+// missing list is created
+func (e *Edit) Append(key string, values ...string) error {
+	segments, err := editSegments(key)
+	if err != nil {
+		return err
+	}
+
+	existing := map[string]bool{}
+	node := e.node(segments)
+	if node != nil {
+		list, ok := node.(*ast.SequenceNode)
+		if !ok {
+			return fmt.Errorf("%s is not a list", key)
+		}
+		for _, item := range list.Values {
+			existing[item.GetToken().Value] = true
+		}
+	}
+
+	added := []any{}
+	for _, value := range values {
+		if !existing[value] {
+			existing[value] = true
+			added = append(added, value)
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	if node == nil {
+		return e.Set(key, added)
+	}
+	list, err := yaml.ValueToNode(added)
+	if err != nil {
+		return err
+	}
+	if err := editPath(segments).MergeFromNode(e.file, list); err != nil {
+		return fmt.Errorf("failed to add to %s: %w", key, err)
+	}
+	e.After = e.render()
+	return nil
+}
+
+// Validate checks the edited file the way Load would, merged with the files
+// it extends. Problems are located in the edited content. root is the
+// project root
+func (e *Edit) Validate(root string) []Issue {
+	fail := func(err error) []Issue {
+		return []Issue{{Severity: SeverityError, Message: err.Error(), File: displayPath(e.File, root)}}
+	}
+
+	raw, err := parseConfig(e.File, e.After)
+	if err != nil {
+		return fail(err)
+	}
+	raw, sources, err := resolveExtends(raw, displayPath(e.File, root), filepath.Dir(e.File), root, []string{e.File})
+	if err != nil {
+		return fail(err)
+	}
+	cfg, result, err := resolveConfig(raw, sources, e.File)
+	if err != nil {
+		return fail(err)
+	}
+
+	l := newLocator(root)
+	l.files[e.File], _ = parser.ParseBytes(e.After, 0)
+	issues := []Issue{}
+	for _, verr := range result.Errors {
+		issue := l.locate(verr, cfg.Sources, e.File)
+		// --set and PSX_* apply to this run, not to the file
+		if isOverrideSource(issue.File) {
+			continue
+		}
+		issue.Severity = SeverityError
+		issues = append(issues, issue)
+	}
+	sortIssues(issues)
+	return issues
+}
+
+// Save writes the edited file, keeping the mode of an existing one
+func (e *Edit) Save() error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(e.File); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(e.File, e.After, mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", e.File, err)
+	}
+	return nil
+}
+
+func (e *Edit) node(segments []string) ast.Node {
+	if len(segments) == 0 {
+		return e.file.Docs[0].Body
+	}
+	node, err := editPath(segments).FilterFile(e.file)
+	if err != nil {
+		return nil
+	}
+	return node
+}
+
+func (e *Edit) render() []byte {
+	return []byte(utils.KeepLayout(e.source, e.file.String()))
+}
+
+func editSegments(key string) ([]string, error) {
+	segments := strings.Split(key, ".")
+	for _, segment := range segments {
+		if segment == "" || strings.ContainsAny(segment, "[]") {
+			return nil, fmt.Errorf("invalid key '%s' - use dotted names like rules.changelog", key)
+		}
+	}
+	if segments[0] == "extends" {
+		return nil, fmt.Errorf("extends can't be edited with psx config - edit %s by hand", DefaultConfigFile)
+	}
+	return segments, nil
+}
+
+func editPath(segments []string) *yaml.Path {
+	builder := (&yaml.PathBuilder{}).Root()
+	for _, segment := range segments {
+		builder = builder.Child(segment)
+	}
+	return builder.Build()
+}
+
+// nestedValue turns a.b.c = v into {a: {b: {c: v}}}
+func nestedValue(segments []string, value any) map[string]any {
+	result := map[string]any{segments[len(segments)-1]: value}
+	for i := len(segments) - 2; i >= 0; i-- {
+		result = map[string]any{segments[i]: result}
+	}
+	return result
+}
+
+// ParseValue reads a value given on the command line the way --set does:
+// as YAML, so "warning", "false", "3" and "[a, b]" get their natural types
+func ParseValue(s string) any {
+	return overrideValue(s)
+}
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+
+	"github.com/m-mdy-m/psx/internal/utils"
+)
+
+// Config file formats. The format is picked from the file name, anything
+// unrecognised is read as YAML
+const (
+	FormatYAML        = "yaml"
+	FormatJSON        = "json"
+	FormatTOML        = "toml"
+	FormatPackageJSON = "package.json" // the "psx" key of package.json
+)
+
+// PackageJSONKey is the package.json key that holds the psx config
+const PackageJSONKey = "psx"
+
+// configFileNames are the config file names psx looks for, in order. A
+// directory may only have one of them; package.json only counts when it has
+// a "psx" key
+var configFileNames = []string{
+	"psx.yml",
+	".psx.yml",
+	"psx.yaml",
+	".psx.yaml",
+	"psx.json",
+	".psx.json",
+	"psx.toml",
+	".psx.toml",
+	"package.json",
+}
+
+func configFormat(path string) string {
+	name := filepath.Base(path)
+	switch {
+	case name == "package.json":
+		return FormatPackageJSON
+	case strings.HasSuffix(name, ".json"):
+		return FormatJSON
+	case strings.HasSuffix(name, ".toml"):
+		return FormatTOML
+	default:
+		return FormatYAML
+	}
+}
+
+// findConfigIn returns the config file in dir, "" when there is none. More
+// than one is an error, since it isn't obvious which of them applies
+func findConfigIn(dir string) (string, error) {
+	found := []string{}
+	for _, name := range configFileNames {
+		path := filepath.Join(dir, name)
+		if exists, info := utils.FileExists(path); !exists || info.IsDir() {
+			continue
+		}
+		if configFormat(path) == FormatPackageJSON && !hasPackageConfig(path) {
+			continue
+		}
+		found = append(found, path)
+	}
+
+	switch len(found) {
+	case 0:
+		return "", nil
+	case 1:
+		return found[0], nil
+	}
+	names := make([]string, len(found))
+	for i, path := range found {
+		names[i] = filepath.Base(path)
+	}
+	return "", fmt.Errorf("multiple config files in %s: %s - keep only one", dir, strings.Join(names, ", "))
+}
+
+func hasPackageConfig(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var pkg map[string]json.RawMessage
+	if json.Unmarshal(data, &pkg) != nil {
+		return false
+	}
+	_, ok := pkg[PackageJSONKey]
+	return ok
+}
+
+// parseConfig turns the contents of a config file into a raw map, the same
+// shape whatever the format
+func parseConfig(path string, data []byte) (map[string]any, error) {
+	raw := map[string]any{}
+
+	switch configFormat(path) {
+	case FormatJSON:
+		if err := decodeJSON(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+	case FormatPackageJSON:
+		var pkg map[string]any
+		if err := decodeJSON(data, &pkg); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		section, exists := pkg[PackageJSONKey]
+		if !exists {
+			return nil, fmt.Errorf("no \"%s\" key in package.json", PackageJSONKey)
+		}
+		config, ok := section.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("\"%s\" in package.json must be an object", PackageJSONKey)
+		}
+		raw = config
+	case FormatTOML:
+		if err := toml.Unmarshal(data, &raw); err != nil {
+			var decodeErr *toml.DecodeError
+			if errors.As(err, &decodeErr) {
+				line, column := decodeErr.Position()
+				return nil, fmt.Errorf("failed to parse TOML: line %d, column %d: %w", line, column, err)
+			}
+			return nil, fmt.Errorf("failed to parse TOML: %w", err)
+		}
+		raw = normalizeTOML(raw).(map[string]any)
+	default:
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+	}
+
+	// JSON editors want a "$schema" key; it isn't a setting
+	delete(raw, "$schema")
+	return raw, nil
+}
+
+// ReadConfigData returns the part of a config file that holds the psx
+// config: the whole file, or the "psx" key of package.json
+func ReadConfigData(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil || configFormat(path) != FormatPackageJSON {
+		return data, err
+	}
+	var pkg map[string]json.RawMessage
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, pkg[PackageJSONKey], "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// decodeJSON keeps whole numbers as ints, like the YAML decoder does
+func decodeJSON(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	normalizeJSON(v)
+	return nil
+}
+
+func normalizeJSON(v any) {
+	switch t := v.(type) {
+	case *map[string]any:
+		normalizeJSON(*t)
+	case map[string]any:
+		for key, value := range t {
+			t[key] = jsonValue(value)
+		}
+	case []any:
+		for i, value := range t {
+			t[i] = jsonValue(value)
+		}
+	}
+}
+
+func jsonValue(v any) any {
+	if n, ok := v.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			return int(i)
+		}
+		f, _ := n.Float64()
+		return f
+	}
+	normalizeJSON(v)
+	return v
+}
+
+// normalizeTOML turns TOML dates into the YYYY-MM-DD strings the YAML
+// decoder produces, so schedules and expiry dates read the same
+func normalizeTOML(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for key, value := range t {
+			t[key] = normalizeTOML(value)
+		}
+	case []any:
+		for i, value := range t {
+			t[i] = normalizeTOML(value)
+		}
+	case []map[string]any:
+		list := make([]any, len(t))
+		for i, value := range t {
+			list[i] = normalizeTOML(value)
+		}
+		return list
+	case toml.LocalDate:
+		return t.String()
+	case toml.LocalDateTime:
+		return t.String()
+	case int64:
+		return int(t)
+	}
+	return v
+}
@@ -0,0 +1,314 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+// Issue is a validation error or warning, located in the file that set the
+// offending value when that file is known
+type Issue struct {
+	Severity Severity // error or warning
+	Field    string
+	Message  string
+	File     string
+	Line     int // 0 when unknown
+	Column   int
+}
+
+func (i Issue) Location() string {
+	if i.Line == 0 {
+		return i.File
+	}
+	return fmt.Sprintf("%s:%d:%d", i.File, i.Line, i.Column)
+}
+
+// ValidateFiles validates the config at file, merged with everything it
+// extends, and every nested config below root. Unlike Load it keeps going
+// after a bad file so all problems are reported at once
+func ValidateFiles(file, root string) []Issue {
+	v := &fileValidator{
+		locator: newLocator(root),
+		root:    root,
+		seen:    map[string]bool{},
+	}
+
+	raw, sources, err := readConfigTree(file, root, nil)
+	if err != nil {
+		v.fail(file, err)
+		return v.issues
+	}
+	cfg := v.validate(raw, sources, file)
+	ignore := []string{}
+	if cfg != nil {
+		ignore = cfg.Ignore
+	}
+	v.validateScopes(root, raw, sources, ignore)
+
+	sortIssues(v.issues)
+	return v.issues
+}
+
+func sortIssues(issues []Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
+
+type fileValidator struct {
+	*locator
+	root   string
+	issues []Issue
+	seen   map[string]bool
+}
+
+func (v *fileValidator) validate(raw map[string]any, sources map[string]string, file string) *Config {
+	cfg, result, err := resolveConfig(raw, sources, file)
+	if err != nil {
+		v.fail(file, err)
+		return nil
+	}
+
+	for _, e := range result.Errors {
+		v.add(SeverityError, e, cfg.Sources, file)
+	}
+	for _, w := range result.Warnings {
+		v.add(SeverityWarning, w, cfg.Sources, file)
+	}
+	return cfg
+}
+
+func (v *fileValidator) validateScopes(dir string, parentRaw map[string]any, parentSources map[string]string, ignore []string) {
+	nested, err := findNestedConfigs(v.root, dir, ignore)
+	if err != nil {
+		v.fail(dir, err)
+		return
+	}
+
+	for _, file := range nested {
+		raw, sources, err := readConfigTree(file, v.root, nil)
+		if err != nil {
+			v.fail(file, err)
+			continue
+		}
+		merged, mergedSources := overlayConfig(parentRaw, parentSources, raw, sources)
+
+		scopeIgnore := ignore
+		if cfg := v.validate(merged, mergedSources, file); cfg != nil {
+			scopeIgnore = cfg.Ignore
+		}
+		v.validateScopes(filepath.Dir(file), merged, mergedSources, scopeIgnore)
+	}
+}
+
+func (v *fileValidator) fail(file string, err error) {
+	v.issues = append(v.issues, Issue{
+		Severity: SeverityError,
+		Message:  err.Error(),
+		File:     displayPath(file, v.root),
+	})
+}
+
+// add records a validation problem. Problems inherited by nested configs
+// are only reported once
+func (v *fileValidator) add(severity Severity, e ValidationError, sources map[string]string, file string) {
+	issue := v.locate(e, sources, file)
+	issue.Severity = severity
+
+	key := issue.Location() + "|" + issue.Message
+	if v.seen[key] {
+		return
+	}
+	v.seen[key] = true
+	v.issues = append(v.issues, issue)
+}
+
+var indexPattern = regexp.MustCompile(`\[\d+\]`)
+
+var profileSourcePattern = regexp.MustCompile(`^(.*) \(profile (.+)\)$`)
+
+// fieldSource finds the file that set field, or the closest parent setting
+// that has a recorded source. Rules set by a profile also return its name
+func fieldSource(field string, sources map[string]string) (string, string) {
+	key := indexPattern.ReplaceAllString(field, "")
+	for key != "" {
+		if source, ok := sources[key]; ok {
+			// merged lists name every file that added to them; the last
+			// one is the most likely place to look
+			if i := strings.LastIndex(source, ", "); i >= 0 {
+				source = source[i+2:]
+			}
+			if m := profileSourcePattern.FindStringSubmatch(source); m != nil {
+				return m[1], m[2]
+			}
+			return source, ""
+		}
+		i := strings.LastIndex(key, ".")
+		if i < 0 {
+			break
+		}
+		key = key[:i]
+	}
+	return "", ""
+}
+
+// locator finds validation problems in the config files that caused them
+type locator struct {
+	root  string
+	files map[string]*ast.File // parsed config files, nil when unreadable
+}
+
+func newLocator(root string) *locator {
+	return &locator{root: root, files: map[string]*ast.File{}}
+}
+
+// locate places a validation problem in the file that set the field,
+// falling back to file when nothing more specific is known
+func (l *locator) locate(e ValidationError, sources map[string]string, file string) Issue {
+	issue := Issue{Field: e.Field, Message: e.Message}
+
+	field := e.Field
+	source, profile := fieldSource(field, sources)
+	if profile != "" {
+		field = "profiles." + profile + "." + field
+	}
+
+	switch {
+	case strings.HasPrefix(source, PresetPrefix), isOverrideSource(source):
+		issue.File = source
+	case source != "":
+		path := source
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(l.root, path)
+		}
+		issue.File = source
+		issue.Line, issue.Column = l.position(path, field)
+	default:
+		issue.File = displayPath(file, l.root)
+		issue.Line, issue.Column = l.position(file, field)
+	}
+	return issue
+}
+
+// position returns the line and column of field in the YAML file at path.
+// When the field isn't in the file (e.g. a required key is missing) the
+// closest parent that is gets used instead
+func (l *locator) position(path, field string) (int, int) {
+	switch configFormat(path) {
+	case FormatTOML:
+		return 0, 0
+	case FormatPackageJSON:
+		field = PackageJSONKey + "." + field
+	}
+
+	file, parsed := l.files[path]
+	if !parsed {
+		if data, err := os.ReadFile(path); err == nil {
+			file, _ = parser.ParseBytes(data, 0)
+		}
+		l.files[path] = file
+	}
+	if file == nil {
+		return 0, 0
+	}
+
+	segments := fieldSegments(field)
+	for n := len(segments); n > 0; n-- {
+		node := findNode(file, segments[:n])
+		if node == nil {
+			continue
+		}
+		// A block mapping or list starts on the line after its key, which
+		// is what the reader is looking for
+		switch node.Type() {
+		case ast.MappingType, ast.MappingValueType, ast.SequenceType:
+			if key := findKey(file, segments[:n]); key != nil {
+				node = key
+			}
+		}
+		pos := node.GetToken().Position
+		return pos.Line, pos.Column
+	}
+	return 0, 0
+}
+
+func findNode(file *ast.File, segments []string) ast.Node {
+	builder := (&yaml.PathBuilder{}).Root()
+	for _, segment := range segments {
+		if index, err := strconv.Atoi(strings.TrimPrefix(segment, "#")); err == nil && strings.HasPrefix(segment, "#") {
+			builder = builder.Index(uint(index))
+		} else {
+			builder = builder.Child(segment)
+		}
+	}
+	node, err := builder.Build().FilterFile(file)
+	if err != nil {
+		return nil
+	}
+	return node
+}
+
+// findKey returns the key node of the last segment, nil for list items
+func findKey(file *ast.File, segments []string) ast.Node {
+	last := segments[len(segments)-1]
+	if strings.HasPrefix(last, "#") {
+		return nil
+	}
+
+	var parent ast.Node
+	if len(segments) == 1 {
+		if len(file.Docs) == 0 {
+			return nil
+		}
+		parent = file.Docs[0].Body
+	} else {
+		parent = findNode(file, segments[:len(segments)-1])
+	}
+
+	var values []*ast.MappingValueNode
+	switch p := parent.(type) {
+	case *ast.MappingNode:
+		values = p.Values
+	case *ast.MappingValueNode:
+		values = []*ast.MappingValueNode{p}
+	}
+	for _, value := range values {
+		if value.Key != nil && value.Key.GetToken().Value == last {
+			return value.Key
+		}
+	}
+	return nil
+}
+
+// fieldSegments splits "custom.rules[0].id" into custom, rules, #0, id
+func fieldSegments(field string) []string {
+	segments := []string{}
+	for _, part := range strings.Split(field, ".") {
+		name, rest, _ := strings.Cut(part, "[")
+		if name != "" {
+			segments = append(segments, name)
+		}
+		for rest != "" {
+			index, after, _ := strings.Cut(rest, "]")
+			segments = append(segments, "#"+index)
+			rest = strings.TrimPrefix(after, "[")
+		}
+	}
+	return segments
+}
@@ -2,9 +2,13 @@ package config
 
 import (
 	"embed"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/goccy/go-yaml"
 	"github.com/m-mdy-m/psx/internal/logger"
@@ -12,9 +16,21 @@ import (
 	"github.com/m-mdy-m/psx/internal/utils"
 )
 
-//go:embed embedded/*.yml
+//go:embed embedded/*.yml embedded/presets/*.yml
 var configFS embed.FS
 
+// Sources for settings that do not come from a user config file
+const (
+	DefaultsSource = "psx.default.yml"
+	MetadataSource = "rules.yml"
+)
+
+// ErrNoConfigFile is returned by FindConfigFile when there is no config
+var ErrNoConfigFile = errors.New("no config file found")
+
+// PresetPrefix marks a built-in preset in extends, e.g. "psx:recommended"
+const PresetPrefix = "psx:"
+
 var (
 	rulesMetadata *RulesMetadata
 	defaultConfig *Config
@@ -38,62 +54,165 @@ func GetRulesMetadata() *RulesMetadata {
 	return rulesMetadata
 }
 func Load(configFile string, projectPath string) (*Config, error) {
-	var userConfig *Config
 	var err error
 	if configFile == "" {
 		logger.Verbose("Searching for config file...")
 		configFile, err = FindConfigFile(projectPath)
-		if err != nil || configFile == "" {
+		if err != nil && !errors.Is(err, ErrNoConfigFile) {
+			return nil, err
+		}
+		if configFile == "" {
 			logger.Info("No config file found, using defaults")
-			return buildConfig(defaultConfig, projectPath, "")
+			state := newLoadState(projectPath)
+			var cfg *Config
+			if len(Overrides()) > 0 {
+				// overrides still need validating and recording
+				sources := map[string]string{}
+				collectSources(defaultRaw(), "", DefaultsSource, sources)
+				cfg, err = loadMerged(defaultRaw(), sources, "", projectPath, state)
+			} else {
+				cfg, err = buildConfig(defaultConfig, projectPath, "")
+			}
+			if err != nil {
+				return nil, err
+			}
+			cfg.Scopes, err = loadScopes(projectPath, projectPath, defaultRaw(), nil, cfg.Ignore, state)
+			return cfg, err
 		}
 		logger.Verbose(fmt.Sprintf("Found config file: %s", configFile))
 	}
-	userConfig, err = readConfigFile(configFile)
+	raw, sources, err := readConfigTree(configFile, projectPath, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config from %s: %w", configFile, err)
 	}
 
 	logger.Verbose(fmt.Sprintf("Loaded user config from: %s", configFile))
 
-	result := Validate(userConfig)
-	if HasWarnings(result) {
-		logger.Warning("Configuration warnings:")
-		for _, warning := range result.Warnings {
+	state := newLoadState(projectPath)
+	cfg, err := loadMerged(raw, sources, configFile, projectPath, state)
+	if err != nil {
+		return nil, err
+	}
+	logger.Success("Configuration loaded and validated")
+
+	cfg.Scopes, err = loadScopes(projectPath, projectPath, raw, sources, cfg.Ignore, state)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadState is shared by a config and its nested scopes while loading
+type loadState struct {
+	locator  *locator
+	reported map[string]bool // warnings already printed
+}
+
+func newLoadState(root string) *loadState {
+	return &loadState{locator: newLocator(root), reported: map[string]bool{}}
+}
+
+// loadMerged validates a merged raw config and builds it for the directory
+// at path. Warnings that were already printed are not repeated, so nested
+// scopes don't repeat what they inherited
+func loadMerged(raw map[string]any, sources map[string]string, file, path string, state *loadState) (*Config, error) {
+	userConfig, result, err := resolveConfig(raw, sources, file)
+	if err != nil {
+		return nil, err
+	}
+	name := file
+	if name == "" {
+		name = DefaultsSource
+	}
+
+	warnings := []string{}
+	for _, warning := range result.Warnings {
+		issue := state.locator.locate(warning, userConfig.Sources, file)
+		message := fmt.Sprintf("%s: %s", issue.Location(), issue.Message)
+		if !state.reported[message] {
+			state.reported[message] = true
+			warnings = append(warnings, message)
+		}
+	}
+	if len(warnings) > 0 {
+		logger.Warning(fmt.Sprintf("Configuration warnings (%s):", name))
+		for _, warning := range warnings {
 			logger.Warning(warning)
 		}
 	}
 	if !IsValid(result) {
-		logger.Error("Configuration validation failed:")
+		logger.Error(fmt.Sprintf("Configuration validation failed (%s):", name))
+		issues := make([]Issue, 0, len(result.Errors))
 		for _, err := range result.Errors {
-			logger.Error(fmt.Sprintf("  [%s] %s", err.Field, err.Message))
+			issues = append(issues, state.locator.locate(err, userConfig.Sources, file))
+		}
+		sortIssues(issues)
+		for _, issue := range issues {
+			logger.Error(fmt.Sprintf("  %s: [%s] %s", issue.Location(), issue.Field, issue.Message))
 		}
 		return nil, fmt.Errorf("config validation failed: %d errors", len(result.Errors))
 	}
 
-	logger.Success("Configuration loaded and validated")
 	projectType := resources.NormalizeProjectType(userConfig.Project.Type)
-
-	return buildConfig(userConfig, projectPath, projectType)
+	return buildConfig(userConfig, path, projectType)
 }
 
-func FindConfigFile(projectPath string) (string, error) {
-	candidates := []string{
-		"psx.yml",
-		".psx.yml",
-		"psx.yaml",
-		".psx.yaml",
+// resolveConfig applies the selected profile to a merged raw config,
+// decodes it and validates the result
+func resolveConfig(raw map[string]any, sources map[string]string, file string) (*Config, ValidationResult, error) {
+	raw, sources, upgraded := upgradeRaw(raw, sources)
+
+	// overrides of a profile have to land before it is applied, the rest
+	// after it so they win over the profile
+	overrides := Overrides()
+	inProfiles := func(key string) bool { return strings.HasPrefix(key, "profiles.") }
+	raw, sources = applyOverrides(raw, sources, overrides, inProfiles)
+
+	profile, err := selectProfile(raw)
+	if err != nil {
+		return nil, ValidationResult{}, fmt.Errorf("%s: %w", file, err)
+	}
+	if profile != "" {
+		logger.Verbose(fmt.Sprintf("Using profile: %s", profile))
+		raw, sources = applyProfile(raw, sources, profile)
+	}
+	raw, sources = applyOverrides(raw, sources, overrides, func(key string) bool { return !inProfiles(key) })
+
+	userConfig, err := decodeConfig(raw)
+	if err != nil {
+		return nil, ValidationResult{}, fmt.Errorf("failed to load config from %s: %w", file, err)
+	}
+	userConfig.File = file
+	userConfig.Sources = sources
+	userConfig.Profile = profile
+	if active := userConfig.ActiveProfile(); active != nil {
+		userConfig.FailOn, userConfig.Output = active.FailOn, active.Output
+	}
+
+	result := Validate(userConfig)
+	result.Warnings = append(result.Warnings, upgraded...)
+	if unknown := unknownKeys(raw); len(unknown) > 0 {
+		result.Errors = append(unknown, result.Errors...)
+		result.Valid = false
 	}
+	if errs := checkOverrides(userConfig, overrides); len(errs) > 0 {
+		result.Errors = append(result.Errors, errs...)
+		result.Valid = false
+	}
+	return userConfig, result, nil
+}
 
+// FindConfigFile looks for a config file (see configFileNames) in the
+// project, then in the git root above it, then in ~/.config/psx
+func FindConfigFile(projectPath string) (string, error) {
 	logger.Verbose(fmt.Sprintf("Looking for config in: %s", projectPath))
 
 	// Check current directory
-	for _, name := range candidates {
-		path := filepath.Join(projectPath, name)
-		if exists, info := utils.FileExists(path); exists && !info.IsDir() {
+	if path, err := findConfigIn(projectPath); err != nil || path != "" {
+		if path != "" {
 			logger.Verbose(fmt.Sprintf("Found config: %s", path))
-			return path, nil
 		}
+		return path, err
 	}
 
 	// Check parent directories up to git root
@@ -111,12 +230,11 @@ func FindConfigFile(projectPath string) (string, error) {
 		gitPath := filepath.Join(current, ".git")
 		if exists, info := utils.FileExists(gitPath); exists && info.IsDir() {
 			logger.Verbose(fmt.Sprintf("Found git root: %s", current))
-			for _, name := range candidates {
-				path := filepath.Join(current, name)
-				if exists, info := utils.FileExists(path); exists && !info.IsDir() {
+			if path, err := findConfigIn(current); err != nil || path != "" {
+				if path != "" {
 					logger.Verbose(fmt.Sprintf("Found config in git root: %s", path))
-					return path, nil
 				}
+				return path, err
 			}
 			break
 		}
@@ -126,49 +244,264 @@ func FindConfigFile(projectPath string) (string, error) {
 	}
 
 	// Check home directory
+	if path, err := homeConfigFile(); err != nil || path != "" {
+		if path != "" {
+			logger.Verbose(fmt.Sprintf("Found config in home: %s", path))
+		}
+		return path, err
+	}
+
+	logger.Verbose("No config file found")
+	return "", ErrNoConfigFile
+}
+
+// HomeConfigFile returns the user-wide config in ~/.config/psx, or "" when
+// there is none
+func HomeConfigFile() string {
+	path, _ := homeConfigFile()
+	return path
+}
+
+func homeConfigFile() (string, error) {
 	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", nil
+	}
+	return findConfigIn(filepath.Join(home, ".config", "psx"))
+}
+
+// Layers lists what the config at path is built from, lowest precedence
+// first: the presets and files it extends, and path itself
+func Layers(path, root string) []string {
+	return appendLayers([]string{}, path, root, nil)
+}
+
+func appendLayers(layers []string, path, root string, chain []string) []string {
+	for _, seen := range chain {
+		if seen == path {
+			return layers
+		}
+	}
+
+	var data []byte
+	var err error
+	name := path
+	if strings.HasPrefix(path, PresetPrefix) {
+		data, err = configFS.ReadFile("embedded/presets/" + strings.TrimPrefix(path, PresetPrefix) + ".yml")
+	} else {
+		data, err = os.ReadFile(path)
+		name = displayPath(path, root)
+	}
+
+	var raw map[string]any
+	if err == nil {
+		raw, err = parseConfig(path, data)
+	}
 	if err == nil {
-		configDir := filepath.Join(home, ".config", "psx")
-		for _, name := range candidates {
-			path := filepath.Join(configDir, name)
-			if exists, info := utils.FileExists(path); exists && !info.IsDir() {
-				logger.Verbose(fmt.Sprintf("Found config in home: %s", path))
-				return path, nil
+		parents, _ := extendsList(raw["extends"])
+		for _, parent := range parents {
+			if !strings.HasPrefix(parent, PresetPrefix) && !filepath.IsAbs(parent) {
+				parent = filepath.Join(filepath.Dir(path), parent)
 			}
+			layers = appendLayers(layers, parent, root, append(chain, path))
 		}
 	}
-
-	logger.Verbose("No config file found")
-	return "", fmt.Errorf("no config file found")
+	return append(layers, name)
 }
 
-// readConfigFile reads and parses a config file
+// readConfigFile reads and parses a config file together with everything
+// it extends
 func readConfigFile(path string) (*Config, error) {
+	raw, _, err := readConfigTree(path, filepath.Dir(path), nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeConfig(raw)
+}
+
+// readConfigTree reads one config file as a raw map and merges the files
+// and presets it extends underneath it. It also reports which file set each
+// value (see collectSources), with paths shown relative to root. chain holds
+// the files already being read, to catch cycles
+func readConfigTree(path, root string, chain []string) (map[string]any, map[string]string, error) {
+	for _, seen := range chain {
+		if seen == path {
+			return nil, nil, fmt.Errorf("extends cycle: %s", strings.Join(append(chain, path), " -> "))
+		}
+	}
+
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	raw, err := parseConfig(path, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logger.Verbose(fmt.Sprintf("Successfully parsed %s config from %s", configFormat(path), path))
+	return resolveExtends(raw, displayPath(path, root), filepath.Dir(path), root, append(chain, path))
+}
+
+// resolveExtends merges the parents listed in raw's extends, left to right,
+// and then raw itself on top, so the extending file always wins
+func resolveExtends(raw map[string]any, source, baseDir, root string, chain []string) (map[string]any, map[string]string, error) {
+	if raw == nil {
+		raw = map[string]any{}
+	}
+	parents, err := extendsList(raw["extends"])
+	if err != nil {
+		return nil, nil, err
+	}
+	delete(raw, "extends")
+
+	merged := map[string]any{}
+	sources := map[string]string{}
+	for _, parent := range parents {
+		var parentRaw map[string]any
+		var parentSources map[string]string
+
+		if strings.HasPrefix(parent, PresetPrefix) {
+			parentRaw, parentSources, err = readPreset(parent, chain)
+		} else {
+			if baseDir == "" {
+				return nil, nil, fmt.Errorf("presets can only extend other presets, not %s", parent)
+			}
+			path := parent
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(baseDir, path)
+			}
+			parentRaw, parentSources, err = readConfigTree(path, root, chain)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("extends %s: %w", parent, err)
+		}
+
+		logger.Verbose(fmt.Sprintf("Extending %s", parent))
+		merged = mergeConfigMaps(merged, parentRaw)
+		for key, value := range parentSources {
+			sources[key] = value
+		}
+	}
+
+	collectSources(raw, "", source, sources)
+	return mergeConfigMaps(merged, raw), sources, nil
+}
+
+func readPreset(name string, chain []string) (map[string]any, map[string]string, error) {
+	for _, seen := range chain {
+		if seen == name {
+			return nil, nil, fmt.Errorf("extends cycle: %s", strings.Join(append(chain, name), " -> "))
+		}
+	}
+
+	data, err := configFS.ReadFile("embedded/presets/" + strings.TrimPrefix(name, PresetPrefix) + ".yml")
+	if err != nil {
+		return nil, nil, fmt.Errorf("unknown preset '%s' - available: %s", name, strings.Join(Presets(), ", "))
+	}
+
+	raw := map[string]any{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse preset %s: %w", name, err)
+	}
+	return resolveExtends(raw, name, "", "", append(chain, name))
+}
+
+// defaultRaw is psx.default.yml as a raw map, the base nested configs
+// cascade from when the project itself has no config file
+func defaultRaw() map[string]any {
+	data, err := configFS.ReadFile("embedded/psx.default.yml")
+	if err != nil {
+		return map[string]any{}
+	}
+	raw := map[string]any{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return map[string]any{}
+	}
+	return raw
+}
+
+// displayPath shows path relative to root when it lives inside it
+func displayPath(path, root string) string {
+	if root == "" {
+		return path
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Presets lists the built-in presets that can be used in extends
+func Presets() []string {
+	entries, _ := configFS.ReadDir("embedded/presets")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, PresetPrefix+strings.TrimSuffix(entry.Name(), ".yml"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func extendsList(value ExtendsList) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok || s == "" {
+				return nil, fmt.Errorf("extends entries must be preset names or file paths")
+			}
+			list = append(list, s)
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("extends must be a string or a list of strings")
+	}
+}
+
+// decodeConfig turns a merged raw map into a Config
+func decodeConfig(raw map[string]any) (*Config, error) {
+	data, err := yaml.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode merged config: %w", err)
 	}
 
 	var cfg Config
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
-
-	logger.Verbose(fmt.Sprintf("Successfully parsed YAML from %s", path))
 	return &cfg, nil
 }
 
 // buildConfig builds a complete config with active rules
 func buildConfig(userCfg *Config, projectPath string, projectType string) (*Config, error) {
 	cfg := &Config{
-		Version:     userCfg.Version,
-		Project:     userCfg.Project,
-		Rules:       userCfg.Rules,
-		Ignore:      userCfg.Ignore,
-		Fix:         userCfg.Fix,
-		Path:        projectPath,
-		Custom:      userCfg.Custom,
-		ActiveRules: make(map[string]*ActiveRule),
+		Version:      userCfg.Version,
+		Project:      userCfg.Project,
+		Rules:        userCfg.Rules,
+		Ignore:       userCfg.Ignore,
+		Fix:          userCfg.Fix,
+		Path:         projectPath,
+		Custom:       userCfg.Custom,
+		Suppressions: userCfg.Suppressions,
+		Profiles:     userCfg.Profiles,
+		Answers:      userCfg.Answers,
+		Profile:      userCfg.Profile,
+		FailOn:       userCfg.FailOn,
+		Output:       userCfg.Output,
+		File:         userCfg.File,
+		Sources:      userCfg.Sources,
+		ActiveRules:  make(map[string]*ActiveRule),
+	}
+	if projectType != "" {
+		cfg.Project.Type = projectType
 	}
 	enabledCount := 0
 	disabledCount := 0
@@ -179,6 +512,7 @@ func buildConfig(userCfg *Config, projectPath string, projectType string) (*Conf
 				ID:       id,
 				Metadata: meta,
 				Severity: meta.DefaultSeverity,
+				Source:   MetadataSource,
 			}
 			enabledCount++
 			logger.Verbose(fmt.Sprintf("Rule %s enabled (default) with severity: %s", id, meta.DefaultSeverity))
@@ -203,26 +537,139 @@ func buildConfig(userCfg *Config, projectPath string, projectType string) (*Conf
 
 			// If disabled
 			if severity == nil {
-				logger.Verbose(fmt.Sprintf("Rule %s is disabled", id))
+				if next := NextEscalation(userSev); next != nil {
+					logger.Verbose(fmt.Sprintf("Rule %s is scheduled to start on %s", id, next.Date.Format(time.DateOnly)))
+				} else {
+					logger.Verbose(fmt.Sprintf("Rule %s is disabled", id))
+				}
 				disabledCount++
 				continue
 			}
 
 			// Enable rule
 			cfg.ActiveRules[id] = &ActiveRule{
-				ID:       id,
-				Metadata: meta,
-				Severity: *severity,
+				ID:         id,
+				Metadata:   meta,
+				Severity:   *severity,
+				Source:     cfg.Source("rules." + id),
+				Escalation: NextEscalation(userSev),
 			}
 			enabledCount++
 			logger.Verbose(fmt.Sprintf("Rule %s enabled with severity: %s", id, *severity))
 		}
 	}
 
+	if userCfg.Custom != nil {
+		for i := range userCfg.Custom.Rules {
+			custom := &userCfg.Custom.Rules[i]
+			severity, err := ParseSeverity(custom.Severity, SeverityWarning)
+			if err != nil {
+				logger.Warning(fmt.Sprintf("Custom rule %s: %v, skipping", custom.ID, err))
+				continue
+			}
+			if severity == nil {
+				logger.Verbose(fmt.Sprintf("Custom rule %s is disabled", custom.ID))
+				disabledCount++
+				continue
+			}
+			cfg.ActiveRules[custom.ID] = &ActiveRule{
+				ID:         custom.ID,
+				Metadata:   customRuleMetadata(custom),
+				Severity:   *severity,
+				Custom:     custom,
+				Source:     cfg.Source("custom.rules"),
+				Escalation: NextEscalation(custom.Severity),
+			}
+			enabledCount++
+			logger.Verbose(fmt.Sprintf("Custom rule %s enabled with severity: %s", custom.ID, *severity))
+		}
+	}
+
+	if userCfg.Custom != nil {
+		for i := range userCfg.Custom.Plugins {
+			plugin := &userCfg.Custom.Plugins[i]
+			severity, err := ParseSeverity(plugin.Severity, SeverityWarning)
+			if err != nil {
+				logger.Warning(fmt.Sprintf("Plugin %s: %v, skipping", plugin.Name, err))
+				continue
+			}
+			if severity == nil {
+				logger.Verbose(fmt.Sprintf("Plugin %s is disabled", plugin.Name))
+				disabledCount++
+				continue
+			}
+			cfg.ActiveRules[plugin.Name] = &ActiveRule{
+				ID: plugin.Name,
+				Metadata: RuleMetadata{
+					ID:       plugin.Name,
+					Category: "plugin",
+					Message:  fmt.Sprintf("Plugin '%s' reported a problem", plugin.Name),
+				},
+				Severity:   *severity,
+				Plugin:     plugin,
+				Source:     cfg.Source("custom.plugins"),
+				Escalation: NextEscalation(plugin.Severity),
+			}
+			enabledCount++
+			logger.Verbose(fmt.Sprintf("Plugin %s enabled with severity: %s", plugin.Name, *severity))
+		}
+	}
+
 	logger.Verbose(fmt.Sprintf("Config built: %d enabled, %d disabled rules", enabledCount, disabledCount))
 	return cfg, nil
 }
 
+// Source tells which config file set a value (e.g. "rules.readme" or
+// "fix.interactive"). Values inside a list or map set as a whole report the
+// source of that setting; anything never set comes from the built-in
+// defaults
+func (c *Config) Source(key string) string {
+	for key != "" {
+		if source, ok := c.Sources[key]; ok {
+			return source
+		}
+		i := strings.LastIndex(key, ".")
+		if i < 0 {
+			break
+		}
+		key = key[:i]
+	}
+	return DefaultsSource
+}
+
+// customRuleMetadata describes a custom rule the same way rules.yml
+// describes a built-in one, so reporters can treat both alike
+func customRuleMetadata(custom *CustomRule) RuleMetadata {
+	message := custom.Message
+	if message == "" {
+		message = fmt.Sprintf("Custom rule '%s' failed", custom.ID)
+	}
+	return RuleMetadata{
+		ID:          custom.ID,
+		Category:    "custom",
+		Description: custom.Description,
+		Message:     message,
+		FixHint:     custom.FixHint,
+	}
+}
+
+// GetCommand picks the command variant for a project type, falling back to
+// "*". An empty result means the rule does not apply to this project type
+func GetCommand(command CommandVariants, projectType string) string {
+	switch c := command.(type) {
+	case string:
+		return c
+	case map[string]any:
+		if variant, ok := c[projectType].(string); ok {
+			return variant
+		}
+		if variant, ok := c["*"].(string); ok {
+			return variant
+		}
+	}
+	return ""
+}
+
 func GetPatterns(patterns any, projectType string) []string {
 	switch p := patterns.(type) {
 	case []any:
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// mergeConfigMaps overlays top onto base and returns the result. Neither
+// input is modified. The rules are the same at every level:
+//   - maps are merged key by key, except a rules map, where the setting of
+//     each rule (a severity or a schedule) in top replaces base whole
+//   - lists of scalars (e.g. ignore) are joined, dropping duplicates
+//   - lists of objects (e.g. custom.rules) are joined, and an entry with the
+//     same id/name/path as an earlier one replaces it in place
+//   - everything else in top replaces base
+func mergeConfigMaps(base, top map[string]any) map[string]any {
+	result := make(map[string]any, len(base)+len(top))
+	for key, value := range base {
+		result[key] = value
+	}
+	for key, value := range top {
+		if key == rulesKey {
+			result[key] = mergeRuleMaps(result[key], value)
+			continue
+		}
+		result[key] = mergeConfigValues(result[key], value)
+	}
+	return result
+}
+
+// rulesKey holds the rule settings at the top level and in a profile
+const rulesKey = "rules"
+
+// mergeRuleMaps overlays the rule settings in top onto base. A schedule
+// in top drops the dates of the base schedule rather than adding to them,
+// so one file never sees another's half of a schedule
+func mergeRuleMaps(base, top any) any {
+	b, ok := base.(map[string]any)
+	t, isMap := top.(map[string]any)
+	if !ok || !isMap {
+		return mergeConfigValues(base, top)
+	}
+	result := make(map[string]any, len(b)+len(t))
+	for id, setting := range b {
+		result[id] = setting
+	}
+	for id, setting := range t {
+		result[id] = setting
+	}
+	return result
+}
+
+func mergeConfigValues(base, top any) any {
+	switch t := top.(type) {
+	case map[string]any:
+		if b, ok := base.(map[string]any); ok {
+			return mergeConfigMaps(b, t)
+		}
+	case []any:
+		if b, ok := base.([]any); ok {
+			return mergeConfigLists(b, t)
+		}
+	}
+	return top
+}
+
+// withDefaultRules spells out the rules a config without any gets, every
+// rule at its default severity, so a profile or override that changes some
+// rules leaves the rest on. Neither input is modified
+func withDefaultRules(raw map[string]any, sources map[string]string) (map[string]any, map[string]string) {
+	if rules, _ := raw[rulesKey].(map[string]any); len(rules) > 0 {
+		return raw, sources
+	}
+
+	defaults := make(map[string]any, len(rulesMetadata.Rules))
+	result := make(map[string]any, len(raw)+1)
+	for key, value := range raw {
+		result[key] = value
+	}
+	result[rulesKey] = defaults
+	resultSources := make(map[string]string, len(sources)+len(rulesMetadata.Rules))
+	for key, value := range sources {
+		resultSources[key] = value
+	}
+	for id, meta := range rulesMetadata.Rules {
+		defaults[id] = string(meta.DefaultSeverity)
+		resultSources[rulesKey+"."+id] = MetadataSource
+	}
+	return result, resultSources
+}
+
+func mergeConfigLists(base, top []any) []any {
+	result := make([]any, 0, len(base)+len(top))
+	index := map[string]int{}
+
+	for _, list := range [][]any{base, top} {
+		for _, item := range list {
+			key := listItemKey(item)
+			if key == "" {
+				result = append(result, item)
+				continue
+			}
+			if i, exists := index[key]; exists {
+				result[i] = item
+				continue
+			}
+			index[key] = len(result)
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// listItemKey identifies list entries that should replace each other when
+// merged. Scalars are their own key; objects use id, name, rule and path
+// (suppressions) or path
+func listItemKey(item any) string {
+	switch v := item.(type) {
+	case map[string]any:
+		if rule, ok := v["rule"].(string); ok && rule != "" {
+			p, _ := v["path"].(string)
+			return "rule=" + rule + "@" + p
+		}
+		for _, field := range []string{"id", "name", "path"} {
+			if s, ok := v[field].(string); ok && s != "" {
+				return field + "=" + s
+			}
+		}
+		return ""
+	case []any:
+		return ""
+	case nil:
+		return ""
+	default:
+		return fmt.Sprintf("%T=%v", v, v)
+	}
+}
+
+// collectSources records source as the origin of every setting in raw,
+// keyed by dotted path. Each rule under a rules map counts as one setting.
+// Lists are joined when merged, so they keep every file that added to them
+func collectSources(raw map[string]any, prefix, source string, into map[string]string) {
+	for key, value := range raw {
+		path := prefix + key
+		if child, ok := value.(map[string]any); ok && !strings.HasSuffix(prefix, rulesKey+".") {
+			collectSources(child, path+".", source, into)
+			continue
+		}
+		if _, isList := value.([]any); isList && into[path] != "" && into[path] != source {
+			into[path] = into[path] + ", " + source
+			continue
+		}
+		into[path] = source
+	}
+}
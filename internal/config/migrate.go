@@ -0,0 +1,347 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+
+	"github.com/m-mdy-m/psx/internal/utils"
+)
+
+// migration lists what changed in one config version
+type migration struct {
+	Version     int
+	RenameRules map[string]string // old rule ID -> new rule ID
+}
+
+// migrations is in version order. A release that renames rules adds an
+// entry here, which makes its version the current one
+var migrations = []migration{}
+
+// CurrentVersion is the newest config format, the version of the last
+// migration. Older configs still load, with a warning; `psx config
+// migrate` rewrites them
+var CurrentVersion = latestVersion(migrations)
+
+func latestVersion(migrations []migration) int {
+	if len(migrations) == 0 {
+		return 1
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// renamedRules maps every rule ID renamed after version from to its
+// current ID, following renames across versions
+func renamedRules(from int) map[string]string {
+	renames := map[string]string{}
+	for _, m := range migrations {
+		if m.Version <= from {
+			continue
+		}
+		for oldID, newID := range m.RenameRules {
+			for id, target := range renames {
+				if target == oldID {
+					renames[id] = newID
+				}
+			}
+			renames[oldID] = newID
+		}
+	}
+	return renames
+}
+
+// rawVersion reads the version of a raw config, 0 when it has none
+func rawVersion(raw map[string]any) int {
+	switch v := raw["version"].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case uint64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+// ruleRename is one renamed rule ID found in a config
+type ruleRename struct {
+	Field    string // where the old ID is, e.g. "rules.old_id"
+	From, To string
+}
+
+func (r ruleRename) String() string {
+	if strings.HasSuffix(r.Field, ".rule") {
+		return fmt.Sprintf("%s: %s -> %s", r.Field, r.From, r.To)
+	}
+	return fmt.Sprintf("%s -> %s", r.Field, strings.TrimSuffix(r.Field, r.From)+r.To)
+}
+
+// findRenames lists the renamed rule IDs used in raw: rule keys at the top
+// level and in profiles, and the rule of each suppression
+func findRenames(raw map[string]any, renames map[string]string) []ruleRename {
+	found := []ruleRename{}
+	ruleKeys := func(prefix string, rules map[string]any) {
+		for _, id := range sortedKeys(rules) {
+			if to, ok := renames[id]; ok {
+				found = append(found, ruleRename{Field: prefix + id, From: id, To: to})
+			}
+		}
+	}
+
+	rules, _ := raw["rules"].(map[string]any)
+	ruleKeys("rules.", rules)
+
+	profiles, _ := raw["profiles"].(map[string]any)
+	for _, name := range sortedKeys(profiles) {
+		profile, _ := profiles[name].(map[string]any)
+		profileRules, _ := profile["rules"].(map[string]any)
+		ruleKeys("profiles."+name+".rules.", profileRules)
+	}
+
+	suppressions, _ := raw["suppressions"].([]any)
+	for i, item := range suppressions {
+		suppression, _ := item.(map[string]any)
+		rule, _ := suppression["rule"].(string)
+		if to, ok := renames[rule]; ok {
+			found = append(found, ruleRename{Field: fmt.Sprintf("suppressions[%d].rule", i), From: rule, To: to})
+		}
+	}
+	return found
+}
+
+// upgradeRaw renames rule IDs an older config still uses, so it keeps
+// loading, and warns about each of them
+func upgradeRaw(raw map[string]any, sources map[string]string) (map[string]any, map[string]string, []ValidationError) {
+	version := rawVersion(raw)
+	if version == 0 || version >= CurrentVersion {
+		return raw, sources, nil
+	}
+
+	found := findRenames(raw, renamedRules(version))
+	if len(found) == 0 {
+		return raw, sources, nil
+	}
+
+	warnings := []ValidationError{}
+	sources = copySources(sources)
+	for _, rename := range found {
+		warnings = append(warnings, ValidationError{
+			Field: rename.Field,
+			Message: fmt.Sprintf("rule '%s' was renamed to '%s' in config version %d - run 'psx config migrate'",
+				rename.From, rename.To, CurrentVersion),
+		})
+
+		if strings.HasSuffix(rename.Field, ".rule") {
+			// suppressions[i].rule
+			list := append([]any{}, raw["suppressions"].([]any)...)
+			i, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rename.Field, "suppressions["), "].rule"))
+			item := map[string]any{}
+			for key, value := range list[i].(map[string]any) {
+				item[key] = value
+			}
+			item["rule"] = rename.To
+			list[i] = item
+			raw = setRaw(raw, []string{"suppressions"}, list)
+			continue
+		}
+
+		path := strings.Split(rename.Field, ".")
+		parent := path[:len(path)-1]
+		rules := map[string]any{}
+		for id, severity := range lookupRaw(raw, strings.Join(parent, ".")).(map[string]any) {
+			rules[id] = severity
+		}
+		rules[rename.To] = rules[rename.From]
+		delete(rules, rename.From)
+		raw = setRaw(raw, parent, rules)
+
+		newField := strings.Join(parent, ".") + "." + rename.To
+		sources[newField] = sources[rename.Field]
+	}
+	return raw, sources, warnings
+}
+
+// Migration is the rewrite of one config file to CurrentVersion
+type Migration struct {
+	File    string
+	From    int // 0 when the file has no version
+	Changes []string
+	Before  []byte
+	After   []byte
+}
+
+// Changed reports whether the file needs rewriting
+func (m *Migration) Changed() bool {
+	return len(m.Changes) > 0
+}
+
+// Migrate works out the rewrite of the config file at path. YAML files are
+// edited in place, keeping comments and key order. Other formats are not
+// rewritten; the error lists what to change by hand
+func Migrate(path string) (*Migration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	raw, err := parseConfig(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	m := &Migration{File: path, From: rawVersion(raw), Before: data, After: data}
+	if m.From > CurrentVersion {
+		return nil, fmt.Errorf("%s: version %d is newer than this psx supports (%d) - upgrade psx", path, m.From, CurrentVersion)
+	}
+
+	// a file without a version may still predate the renames
+	from := max(m.From, 1)
+	renames := findRenames(raw, renamedRules(from))
+	for _, rename := range renames {
+		m.Changes = append(m.Changes, rename.String())
+	}
+	if m.From != 0 && m.From < CurrentVersion {
+		m.Changes = append(m.Changes, fmt.Sprintf("version: %d -> %d", m.From, CurrentVersion))
+	}
+	if !m.Changed() {
+		return m, nil
+	}
+
+	if configFormat(path) != FormatYAML {
+		return nil, fmt.Errorf("%s: only YAML configs can be migrated automatically - change by hand:\n  %s",
+			path, strings.Join(m.Changes, "\n  "))
+	}
+
+	file, err := parser.ParseBytes(data, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to parse YAML: %w", path, err)
+	}
+	if len(file.Docs) == 0 {
+		return m, nil
+	}
+	if err := rewriteConfig(file.Docs[0].Body, renames, m.From); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	m.After = []byte(utils.KeepLayout(string(data), file.String()))
+	return m, nil
+}
+
+// rewriteConfig applies renames and the version bump to a parsed config
+func rewriteConfig(body ast.Node, renames []ruleRename, from int) error {
+	for _, rename := range renames {
+		segments := fieldSegments(rename.Field)
+		last := segments[len(segments)-1]
+
+		if last == "rule" {
+			node, ok := yamlChild(body, segments).(*ast.StringNode)
+			if !ok {
+				return fmt.Errorf("%s: expected a rule ID", rename.Field)
+			}
+			setString(node, rename.To)
+			continue
+		}
+
+		parent := yamlChild(body, segments[:len(segments)-1])
+		if yamlChild(parent, []string{rename.To}) != nil {
+			return fmt.Errorf("both %s and %s are set - remove one first", rename.From, rename.To)
+		}
+		for _, value := range yamlMapping(parent) {
+			if key, ok := value.Key.(*ast.StringNode); ok && key.Value == rename.From {
+				setString(key, rename.To)
+			}
+		}
+	}
+
+	if from != 0 && from < CurrentVersion {
+		if node, ok := yamlChild(body, []string{"version"}).(*ast.IntegerNode); ok {
+			node.Value = uint64(CurrentVersion)
+			node.Token.Value = strconv.Itoa(CurrentVersion)
+		}
+	}
+	return nil
+}
+
+func setString(node *ast.StringNode, value string) {
+	node.Value = value
+	node.Token.Value = value
+}
+
+// yamlMapping returns the key/value pairs of a mapping node
+func yamlMapping(node ast.Node) []*ast.MappingValueNode {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		return n.Values
+	case *ast.MappingValueNode:
+		return []*ast.MappingValueNode{n}
+	}
+	return nil
+}
+
+// yamlChild follows segments (see fieldSegments) down from node
+func yamlChild(node ast.Node, segments []string) ast.Node {
+	for _, segment := range segments {
+		if index, err := strconv.Atoi(strings.TrimPrefix(segment, "#")); err == nil && strings.HasPrefix(segment, "#") {
+			list, ok := node.(*ast.SequenceNode)
+			if !ok || index >= len(list.Values) {
+				return nil
+			}
+			node = list.Values[index]
+			continue
+		}
+
+		var next ast.Node
+		for _, value := range yamlMapping(node) {
+			if value.Key != nil && value.Key.GetToken().Value == segment {
+				next = value.Value
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+// MigrationFiles lists the config files of a project: the config itself and
+// every nested config below root
+func MigrationFiles(configFile, root string) ([]string, error) {
+	files := []string{configFile}
+
+	ignore := []string{}
+	if raw, _, err := readConfigTree(configFile, root, nil); err == nil {
+		if cfg, err := decodeConfig(raw); err == nil {
+			ignore = cfg.Ignore
+		}
+	}
+
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		nested, err := findNestedConfigs(root, dir, ignore)
+		if err != nil {
+			return err
+		}
+		for _, file := range nested {
+			files = append(files, file)
+			if err := walk(filepath.Dir(file)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(root); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files[1:])
+	return files, nil
+}
@@ -0,0 +1,203 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/m-mdy-m/psx/internal/utils"
+)
+
+// useMigrations replaces the migration table for the rest of the test
+func useMigrations(t *testing.T, table []migration) {
+	t.Helper()
+	saved, savedVersion := migrations, CurrentVersion
+	migrations, CurrentVersion = table, latestVersion(table)
+	t.Cleanup(func() { migrations, CurrentVersion = saved, savedVersion })
+}
+
+var testMigrations = []migration{
+	{Version: 2, RenameRules: map[string]string{"old_readme": "mid_readme"}},
+	{Version: 3, RenameRules: map[string]string{"mid_readme": "readme", "old_license": "license"}},
+}
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMigrate(t *testing.T) {
+	useMigrations(t, testMigrations)
+
+	tests := []struct {
+		name    string
+		file    string
+		config  string
+		want    string // the migrated file, "" when it is up to date
+		changes []string
+		err     string // part of the error, "" for none
+	}{
+		{
+			name: "renames across versions, keeping comments",
+			file: "psx.yml",
+			config: `# team config
+version: 1  # format
+
+rules:
+  old_readme: error   # must have
+  old_license: warning
+  changelog: info
+
+profiles:
+  ci:
+    rules:
+      mid_readme: error
+
+suppressions:
+  - rule: old_license # still deciding
+    reason: "Not chosen yet"
+`,
+			want: `# team config
+version: 3  # format
+
+rules:
+  readme: error   # must have
+  license: warning
+  changelog: info
+
+profiles:
+  ci:
+    rules:
+      readme: error
+
+suppressions:
+  - rule: license # still deciding
+    reason: "Not chosen yet"
+`,
+			changes: []string{
+				"rules.old_license -> rules.license",
+				"rules.old_readme -> rules.readme",
+				"profiles.ci.rules.mid_readme -> profiles.ci.rules.readme",
+				"suppressions[0].rule: old_license -> license",
+				"version: 1 -> 3",
+			},
+		},
+		{
+			name: "only the version is out of date",
+			file: "psx.yml",
+			config: `version: 2
+rules:
+  readme: error
+`,
+			want: `version: 3
+rules:
+  readme: error
+`,
+			changes: []string{"version: 2 -> 3"},
+		},
+		{
+			name: "up to date",
+			file: "psx.yml",
+			config: `version: 3
+rules:
+  old_readme: error
+`,
+		},
+		{
+			name: "old and new ID both set",
+			file: "psx.yml",
+			config: `version: 1
+rules:
+  old_license: error
+  license: warning
+`,
+			err: "both old_license and license are set",
+		},
+		{
+			name:   "not YAML",
+			file:   "psx.json",
+			config: `{"version": 1, "rules": {"old_license": "error"}}`,
+			err:    "change by hand:\n  rules.old_license -> rules.license\n  version: 1 -> 3",
+		},
+		{
+			name:   "newer than supported",
+			file:   "psx.yml",
+			config: "version: 4\n",
+			err:    "version 4 is newer than this psx supports (3)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Migrate(writeConfig(t, tt.file, tt.config))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.want == "" {
+				if m.Changed() {
+					t.Fatalf("got changes %v, want none", m.Changes)
+				}
+				return
+			}
+			if got := strings.Join(m.Changes, "\n"); got != strings.Join(tt.changes, "\n") {
+				t.Errorf("changes =\n%s\nwant\n%s", got, strings.Join(tt.changes, "\n"))
+			}
+			if string(m.After) != tt.want {
+				t.Errorf("migrated file =\n%s\nwant\n%s", m.After, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateDiff(t *testing.T) {
+	useMigrations(t, testMigrations)
+
+	m, err := Migrate(writeConfig(t, "psx.yml", "version: 1\nrules:\n  old_readme: error  # must have\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := utils.UnifiedDiff("a/psx.yml", "b/psx.yml", string(m.Before), string(m.After))
+	for _, line := range []string{
+		"-version: 1",
+		"+version: 3",
+		"-  old_readme: error  # must have",
+		"+  readme: error  # must have",
+	} {
+		if !strings.Contains(diff, line+"\n") {
+			t.Errorf("diff has no line %q:\n%s", line, diff)
+		}
+	}
+}
+
+func TestLoadRenamedRules(t *testing.T) {
+	useMigrations(t, testMigrations)
+
+	var raw = map[string]any{
+		"version": uint64(1),
+		"rules":   map[string]any{"old_readme": "error", "changelog": "warning"},
+	}
+	userConfig, result, err := resolveConfig(raw, map[string]string{}, "psx.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Warnings) == 0 || !strings.Contains(result.Warnings[len(result.Warnings)-1].Message, "'old_readme' was renamed to 'readme'") {
+		t.Errorf("got warnings %v, want one about the rename of old_readme", result.Warnings)
+	}
+	cfg, err := buildConfig(userConfig, t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkActiveRules(t, cfg, map[string]Severity{"readme": SeverityError, "changelog": SeverityWarning}, 2)
+}
@@ -0,0 +1,274 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/m-mdy-m/psx/internal/logger"
+)
+
+// Override sets one setting from the environment (PSX_RULES_CHANGELOG=error)
+// or from --set (rules.changelog=error). Overrides win over every config
+// file and the selected profile, and replace lists instead of joining them
+type Override struct {
+	Key    string // dotted setting, e.g. "rules.changelog"
+	Value  any
+	Source string // "env:PSX_RULES_CHANGELOG" or "flag:--set rules.changelog"
+}
+
+const (
+	EnvPrefix = "PSX_"
+
+	envSourcePrefix  = "env:"
+	flagSourcePrefix = "flag:"
+)
+
+// Check settings that can be overridden but don't live in the config file
+// (outside of profiles)
+const (
+	SettingFailOn = "fail_on"
+	SettingOutput = "output"
+)
+
+// reservedEnv are set by psx for the commands and plugins it runs, so they
+// are never read as overrides
+var reservedEnv = map[string]bool{
+	"PSX_PROJECT_PATH":    true,
+	"PSX_PROJECT_TYPE":    true,
+	"PSX_PLUGIN_PROTOCOL": true,
+}
+
+var flagOverrides []Override
+
+// UseOverrides sets the --set overrides, each written as key=value
+func UseOverrides(set []string) error {
+	flagOverrides = nil
+	for _, entry := range set {
+		key, value, found := strings.Cut(entry, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return fmt.Errorf("invalid --set '%s' - use key=value, e.g. rules.changelog=error", entry)
+		}
+		if key == "extends" || strings.HasPrefix(key, "extends.") {
+			return fmt.Errorf("invalid --set '%s' - extends can't be overridden", entry)
+		}
+		flagOverrides = append(flagOverrides, Override{
+			Key:    key,
+			Value:  overrideValue(value),
+			Source: flagSourcePrefix + "--set " + key,
+		})
+	}
+	return nil
+}
+
+// Overrides lists the overrides in effect, environment first so --set wins
+func Overrides() []Override {
+	return append(envOverrides(), flagOverrides...)
+}
+
+func envOverrides() []Override {
+	overrides := []Override{}
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		if !strings.HasPrefix(name, EnvPrefix) || reservedEnv[name] {
+			continue
+		}
+		key, ok := envSettingKey(strings.ToLower(strings.TrimPrefix(name, EnvPrefix)))
+		if !ok {
+			logger.Verbose(fmt.Sprintf("Ignoring %s: no matching config setting", name))
+			continue
+		}
+		overrides = append(overrides, Override{
+			Key:    key,
+			Value:  overrideValue(value),
+			Source: envSourcePrefix + name,
+		})
+	}
+	sort.Slice(overrides, func(i, j int) bool { return overrides[i].Source < overrides[j].Source })
+	return overrides
+}
+
+// envSettingKey maps the lower-cased rest of a PSX_ variable onto a setting,
+// e.g. rules_docker_compose -> rules.docker_compose. Underscores are both
+// separators and part of names, so the config types decide where to split
+func envSettingKey(name string) (string, bool) {
+	if name == SettingFailOn || name == SettingOutput {
+		return name, true
+	}
+	if name == "extends" || strings.HasPrefix(name, "extends_") {
+		return "", false
+	}
+	return envKey(name, reflect.TypeOf(Config{}))
+}
+
+func envKey(rest string, t reflect.Type) (string, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := yamlFields(t)
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		// longest first, so fix_hint isn't read as fix + hint
+		sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+
+		for _, name := range names {
+			if rest == name {
+				return name, true
+			}
+			if tail, ok := strings.CutPrefix(rest, name+"_"); ok {
+				if sub, ok := envKey(tail, fields[name]); ok {
+					return name + "." + sub, true
+				}
+			}
+		}
+	case reflect.Map:
+		elem := t.Elem()
+		for elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct {
+			// e.g. a rule ID, which may itself contain underscores
+			return rest, rest != ""
+		}
+		key, tail, found := strings.Cut(rest, "_")
+		if !found {
+			return "", false
+		}
+		if sub, ok := envKey(tail, elem); ok {
+			return key + "." + sub, true
+		}
+	}
+	return "", false
+}
+
+// overrideValue reads a value the way it would be read from psx.yml, so
+// "false", "[dist, build]" and "{warning: now}" work. Anything that isn't
+// valid YAML stays a string
+func overrideValue(s string) any {
+	if strings.TrimSpace(s) == "" {
+		return s
+	}
+	var value any
+	if err := yaml.Unmarshal([]byte(s), &value); err != nil {
+		return s
+	}
+	return value
+}
+
+// isOverrideSource reports whether a source names an override rather than
+// a config file
+func isOverrideSource(source string) bool {
+	return strings.HasPrefix(source, envSourcePrefix) || strings.HasPrefix(source, flagSourcePrefix)
+}
+
+// applyOverrides sets the overrides whose key passes keep in a copy of raw.
+// Check settings (fail_on, output) aren't part of raw; see checkOverrides
+func applyOverrides(raw map[string]any, sources map[string]string, overrides []Override, keep func(key string) bool) (map[string]any, map[string]string) {
+	applied := false
+	for _, override := range overrides {
+		if isCheckSetting(override.Key) || !keep(override.Key) {
+			continue
+		}
+		if !applied {
+			sources = copySources(sources)
+			applied = true
+		}
+		path := strings.Split(override.Key, ".")
+		// an override changes one rule of the rules the config gets,
+		// which are the default rules when it sets none
+		if path[0] == rulesKey && len(path) > 1 {
+			raw, sources = withDefaultRules(raw, sources)
+		}
+		// a setting the override creates from nothing is credited to it
+		if created := createdKey(raw, path); created != "" {
+			sources[created] = override.Source
+		}
+		raw = setRaw(raw, path, override.Value)
+
+		for key := range sources {
+			if key == override.Key || strings.HasPrefix(key, override.Key+".") {
+				delete(sources, key)
+			}
+		}
+		parent, last := "", override.Key
+		if i := strings.LastIndex(override.Key, "."); i >= 0 {
+			parent, last = override.Key[:i+1], override.Key[i+1:]
+		}
+		collectSources(map[string]any{last: override.Value}, parent, override.Source, sources)
+	}
+	return raw, sources
+}
+
+// checkOverrides applies fail_on and output overrides to cfg and validates
+// them like the same settings in a profile
+func checkOverrides(cfg *Config, overrides []Override) []ValidationError {
+	var failOn, output string
+	for _, override := range overrides {
+		if !isCheckSetting(override.Key) {
+			continue
+		}
+		if failOn == "" && output == "" {
+			cfg.Sources = copySources(cfg.Sources)
+		}
+		value := fmt.Sprint(override.Value)
+		if override.Key == SettingFailOn {
+			cfg.FailOn, failOn = value, value
+		} else {
+			cfg.Output, output = value, value
+		}
+		cfg.Sources[override.Key] = override.Source
+	}
+	return validateCheckSettings("", failOn, output)
+}
+
+func isCheckSetting(key string) bool {
+	return key == SettingFailOn || key == SettingOutput
+}
+
+// setRaw returns raw with value at path, copying the maps along the way so
+// the parent configs that share them are left alone
+func setRaw(raw map[string]any, path []string, value any) map[string]any {
+	result := make(map[string]any, len(raw)+1)
+	for key, v := range raw {
+		result[key] = v
+	}
+	if len(path) == 1 {
+		result[path[0]] = value
+		return result
+	}
+	child, _ := result[path[0]].(map[string]any)
+	result[path[0]] = setRaw(child, path[1:], value)
+	return result
+}
+
+// createdKey returns the first map along path that raw doesn't have yet,
+// "" when only the last key is new or it already exists
+func createdKey(raw map[string]any, path []string) string {
+	current := raw
+	for i, key := range path[:len(path)-1] {
+		child, ok := current[key].(map[string]any)
+		if !ok {
+			return strings.Join(path[:i+1], ".")
+		}
+		current = child
+	}
+	return ""
+}
+
+func copySources(sources map[string]string) map[string]string {
+	result := make(map[string]string, len(sources))
+	for key, value := range sources {
+		result[key] = value
+	}
+	return result
+}
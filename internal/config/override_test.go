@@ -0,0 +1,52 @@
+package config
+
+import "testing"
+
+func TestRuleOverrides(t *testing.T) {
+	readme := rulesMetadata.Rules["readme"].DefaultSeverity
+
+	tests := []struct {
+		name   string
+		config string
+		env    map[string]string
+		set    []string
+		want   map[string]Severity // active rules to check, "" for disabled
+		total  int                 // number of active rules, 0 for all
+	}{
+		{
+			name:   "env on a config without rules",
+			config: "version: 1\n",
+			env:    map[string]string{"PSX_RULES_LICENSE": "error"},
+			want:   map[string]Severity{"license": SeverityError, "readme": readme},
+		},
+		{
+			name:   "--set on a config without rules",
+			config: "version: 1\n",
+			set:    []string{"rules.readme=false"},
+			want:   map[string]Severity{"readme": ""},
+			total:  len(rulesMetadata.Rules) - 1,
+		},
+		{
+			name:   "--set on a config with rules",
+			config: "rules:\n  readme: warning\n",
+			set:    []string{"rules.license=error"},
+			want:   map[string]Severity{"readme": SeverityWarning, "license": SeverityError, "changelog": ""},
+			total:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			if err := UseOverrides(tt.set); err != nil {
+				t.Fatal(err)
+			}
+			defer UseOverrides(nil)
+
+			cfg := loadTestConfig(t, tt.config)
+			checkActiveRules(t, cfg, tt.want, tt.total)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	ProfileCI    = "ci"
+	ProfileLocal = "local"
+)
+
+var requestedProfile string
+
+// UseProfile selects the profile named by --profile. Without one, "ci" is
+// used when the CI environment variable is set and "local" otherwise, as
+// long as the config defines it
+func UseProfile(name string) {
+	requestedProfile = name
+}
+
+// ActiveProfile returns the selected profile, or nil when none applies
+func (c *Config) ActiveProfile() *Profile {
+	if c.Profile == "" {
+		return nil
+	}
+	profile, exists := c.Profiles[c.Profile]
+	if !exists {
+		return nil
+	}
+	return &profile
+}
+
+func selectProfile(raw map[string]any) (string, error) {
+	profiles, _ := raw["profiles"].(map[string]any)
+
+	if requestedProfile != "" {
+		if _, exists := profiles[requestedProfile]; !exists {
+			return "", fmt.Errorf("profile '%s' is not defined (available: %s)",
+				requestedProfile, strings.Join(profileNames(profiles), ", "))
+		}
+		return requestedProfile, nil
+	}
+
+	auto := ProfileLocal
+	if os.Getenv("CI") != "" {
+		auto = ProfileCI
+	}
+	if _, exists := profiles[auto]; exists {
+		return auto, nil
+	}
+	return "", nil
+}
+
+// applyProfile overlays the rules of a profile onto the top-level rules,
+// or the default rules when the config sets none. Each rule the profile
+// sets replaces its top-level setting whole; the others are kept
+func applyProfile(raw map[string]any, sources map[string]string, name string) (map[string]any, map[string]string) {
+	profile, _ := raw["profiles"].(map[string]any)[name].(map[string]any)
+	rules, _ := profile["rules"].(map[string]any)
+	if len(rules) == 0 {
+		return raw, sources
+	}
+	raw, sources = withDefaultRules(raw, sources)
+
+	merged := make(map[string]any, len(raw))
+	for key, value := range raw {
+		merged[key] = value
+	}
+	merged[rulesKey] = mergeRuleMaps(raw[rulesKey], rules)
+	mergedSources := make(map[string]string, len(sources)+len(rules))
+	for key, value := range sources {
+		mergedSources[key] = value
+	}
+	for id := range rules {
+		mergedSources["rules."+id] = fmt.Sprintf("%s (profile %s)", sources["profiles."+name+".rules."+id], name)
+	}
+	return merged, mergedSources
+}
+
+func profileNames(profiles map[string]any) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		names = append(names, "none")
+	}
+	return names
+}
+
+func validateProfiles(profiles map[string]Profile) ([]ValidationError, []ValidationError) {
+	errors := []ValidationError{}
+	warnings := []ValidationError{}
+
+	for name, profile := range profiles {
+		field := "profiles." + name
+
+		// besides fail_on/output, a profile may set rules, checked like
+		// the top-level ones
+		if len(profile.Rules) > 0 {
+			errs, warns := ValidateRules(profile.Rules)
+			for _, err := range errs {
+				err.Field = field + "." + err.Field
+				errors = append(errors, err)
+			}
+			for _, warn := range warns {
+				warn.Field = field + "." + warn.Field
+				warn.Message = fmt.Sprintf("%s: %s", field, warn.Message)
+				warnings = append(warnings, warn)
+			}
+		}
+
+		errors = append(errors, validateCheckSettings(field+".", profile.FailOn, profile.Output)...)
+	}
+
+	return errors, warnings
+}
+
+// validateCheckSettings checks the defaults for check --fail-on and
+// --output, set by a profile or an override
+func validateCheckSettings(prefix, failOn, output string) []ValidationError {
+	errors := []ValidationError{}
+
+	switch failOn {
+	case "", "error", "warning":
+	default:
+		errors = append(errors, ValidationError{
+			Field:   prefix + SettingFailOn,
+			Message: fmt.Sprintf("invalid fail_on '%s' - use error or warning", failOn),
+		})
+	}
+
+	switch output {
+	case "", "table", "json":
+	default:
+		errors = append(errors, ValidationError{
+			Field:   prefix + SettingOutput,
+			Message: fmt.Sprintf("invalid output '%s' - use table or json", output),
+		})
+	}
+	return errors
+}
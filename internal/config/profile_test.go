@@ -0,0 +1,106 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestProfileWithPartialRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   map[string]Severity // active rules to check, "" for disabled
+		total  int                 // number of active rules, 0 for all
+	}{
+		{
+			name: "no rules in the config",
+			config: `
+profiles:
+  ci:
+    rules:
+      changelog: error
+`,
+			want: map[string]Severity{"changelog": SeverityError, "readme": rulesMetadata.Rules["readme"].DefaultSeverity},
+		},
+		{
+			name: "rules in the config",
+			config: `
+rules:
+  readme: warning
+  license: error
+profiles:
+  ci:
+    rules:
+      readme: error
+`,
+			want:  map[string]Severity{"readme": SeverityError, "license": SeverityError, "changelog": ""},
+			total: 2,
+		},
+		{
+			name: "profile disables a rule",
+			config: `
+profiles:
+  ci:
+    rules:
+      changelog: false
+`,
+			want:  map[string]Severity{"changelog": "", "readme": rulesMetadata.Rules["readme"].DefaultSeverity},
+			total: len(rulesMetadata.Rules) - 1,
+		},
+	}
+
+	UseProfile(ProfileCI)
+	defer UseProfile("")
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadTestConfig(t, tt.config)
+			checkActiveRules(t, cfg, tt.want, tt.total)
+		})
+	}
+}
+
+// loadTestConfig resolves and builds config the way Load does
+func loadTestConfig(t *testing.T, config string) *Config {
+	t.Helper()
+	var raw map[string]any
+	if err := yaml.Unmarshal([]byte(config), &raw); err != nil {
+		t.Fatal(err)
+	}
+	userConfig, result, err := resolveConfig(raw, map[string]string{}, "psx.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsValid(result) {
+		t.Fatalf("config is invalid: %v", result.Errors)
+	}
+	cfg, err := buildConfig(userConfig, t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+// checkActiveRules compares the severities of the rules in want, "" for
+// disabled, and the number of active rules, 0 for all of them
+func checkActiveRules(t *testing.T, cfg *Config, want map[string]Severity, total int) {
+	t.Helper()
+	if total == 0 {
+		total = len(rulesMetadata.Rules)
+	}
+	if len(cfg.ActiveRules) != total {
+		t.Errorf("got %d active rules, want %d", len(cfg.ActiveRules), total)
+	}
+	for id, severity := range want {
+		rule, active := cfg.ActiveRules[id]
+		switch {
+		case severity == "" && active:
+			t.Errorf("%s is active with %s, want it disabled", id, rule.Severity)
+		case severity != "" && !active:
+			t.Errorf("%s is disabled, want %s", id, severity)
+		case severity != "" && rule.Severity != severity:
+			t.Errorf("%s has severity %s, want %s", id, rule.Severity, severity)
+		}
+	}
+}
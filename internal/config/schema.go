@@ -0,0 +1,207 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/m-mdy-m/psx/internal/resources"
+)
+
+const SchemaURL = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	rulesSeverityType   = reflect.TypeOf((*RulesSeverity)(nil)).Elem()
+	extendsListType     = reflect.TypeOf((*ExtendsList)(nil)).Elem()
+	commandVariantsType = reflect.TypeOf((*CommandVariants)(nil)).Elem()
+)
+
+// schemaDescriptions documents settings by their path in psx.yml. List
+// items share the path of their list; map values use "*"
+var schemaDescriptions = map[string]string{
+	"extends":              "Presets (psx:recommended, psx:strict, psx:oss, psx:minimal) or config files to build on, merged left to right",
+	"version":              "Config format version",
+	"project.type":         "Project type; selects language-specific patterns and templates",
+	"rules":                "Built-in rules to enable, with their severity",
+	"ignore":               "Paths and globs psx skips",
+	"fix.interactive":      "Ask before each fix",
+	"fix.backup":           "Back up files before modifying them",
+	"custom.files":         "Files psx fix creates with the given content",
+	"custom.folders":       "Folder trees psx fix creates",
+	"custom.rules":         "Project-specific rules written as Starlark scripts or commands",
+	"custom.plugins":       "External rule executables that speak the psx plugin protocol",
+	"custom.concurrency":   "How many command rules run at once (default: CPU count)",
+	"custom.rules.command": "Shell command, or a map of project type to command with \"*\" as fallback. Exit code 0 passes",
+	"custom.rules.timeout": "Duration such as 30s or 2m",
+	"custom.rules.workdir": "Directory to run the command in, relative to the project",
+	"custom.plugins.path":  "Plugin executable; defaults to .psx/plugins/psx-rule-<name> or psx-rule-<name> on $PATH",
+	"suppressions":         "Known violations to accept, each with a reason",
+	"suppressions.path":    "Limit the suppression to findings under this path (globs allowed)",
+	"suppressions.expires": "Last day the suppression applies (YYYY-MM-DD)",
+	"profiles":             "Per-environment overrides selected with --profile, or ci/local automatically",
+	"profiles.*.fail_on":   "Default for psx check --fail-on",
+	"profiles.*.output":    "Default for psx check --output",
+	"answers":              "Answers to the questions psx fix asks, by key (e.g. ci_platform: gitlab)",
+}
+
+// schemaRequired lists the keys objects at a path can't do without
+var schemaRequired = map[string][]string{
+	"custom.files":   {"path"},
+	"custom.folders": {"path"},
+	"custom.rules":   {"id"},
+	"custom.plugins": {"name"},
+	"suppressions":   {"rule", "reason"},
+}
+
+// Schema returns a JSON Schema for psx.yml, generated from the Config types
+// and the rule IDs in rules.yml
+func Schema() map[string]any {
+	schema := schemaFor(reflect.TypeOf(Config{}), "")
+	schema["$schema"] = SchemaURL
+	schema["title"] = "psx configuration"
+	schema["properties"].(map[string]any)["version"].(map[string]any)["maximum"] = CurrentVersion
+	// lets psx.json point at the schema; psx ignores it
+	schema["properties"].(map[string]any)["$schema"] = map[string]any{"type": "string"}
+	return schema
+}
+
+func schemaFor(t reflect.Type, path string) map[string]any {
+	var schema map[string]any
+
+	switch {
+	case t == rulesSeverityType:
+		schema = severitySchema()
+	case t == extendsListType:
+		schema = map[string]any{
+			"oneOf": []any{
+				map[string]any{"type": "string"},
+				map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			},
+		}
+	case t == commandVariantsType:
+		schema = map[string]any{
+			"oneOf": []any{
+				map[string]any{"type": "string"},
+				map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+			},
+		}
+	case path == "project.type":
+		schema = map[string]any{"type": "string", "examples": resources.ProjectTypes()}
+	case path == "suppressions.expires":
+		schema = map[string]any{"type": "string", "format": "date", "pattern": `^\d{4}-\d{2}-\d{2}$`}
+	case path == "profiles.*.fail_on":
+		schema = map[string]any{"enum": []any{"error", "warning"}}
+	case path == "profiles.*.output":
+		schema = map[string]any{"enum": []any{"table", "json"}}
+	default:
+		schema = schemaForKind(t, path)
+	}
+
+	if description, ok := schemaDescriptions[path]; ok {
+		schema["description"] = description
+	}
+	return schema
+}
+
+func schemaForKind(t reflect.Type, path string) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem(), path)
+	case reflect.Struct:
+		return structSchema(t, path)
+	case reflect.Map:
+		if t.Elem() == rulesSeverityType {
+			return rulesSchema()
+		}
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": schemaFor(t.Elem(), joinSchemaPath(path, "*")),
+		}
+	case reflect.Slice:
+		items := schemaFor(t.Elem(), path)
+		delete(items, "description") // already on the list
+		return map[string]any{"type": "array", "items": items}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	default:
+		return map[string]any{}
+	}
+}
+
+func structSchema(t reflect.Type, path string) map[string]any {
+	properties := map[string]any{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		properties[name] = schemaFor(field.Type, joinSchemaPath(path, name))
+	}
+
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if required, ok := schemaRequired[path]; ok {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// rulesSchema lists every built-in rule so editors can complete rule IDs
+func rulesSchema() map[string]any {
+	metadata := GetRulesMetadata()
+	ids := make([]string, 0, len(metadata.Rules))
+	for id := range metadata.Rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	properties := map[string]any{}
+	for _, id := range ids {
+		meta := metadata.Rules[id]
+		rule := severitySchema()
+		rule["description"] = fmt.Sprintf("%s (%s, default: %s)", meta.Description, meta.Category, meta.DefaultSeverity)
+		properties[id] = rule
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func severitySchema() map[string]any {
+	severities := []any{string(SeverityError), string(SeverityWarning), string(SeverityInfo)}
+	return map[string]any{
+		"oneOf": []any{
+			map[string]any{"enum": severities},
+			map[string]any{"const": false, "description": "Disable the rule"},
+			map[string]any{
+				"type":          "object",
+				"description":   "Severity schedule, e.g. {warning: now, error: 2027-01-01}",
+				"propertyNames": map[string]any{"enum": severities},
+				"additionalProperties": map[string]any{
+					"type":    "string",
+					"pattern": `^(now|\d{4}-\d{2}-\d{2})$`,
+				},
+				"minProperties": 1,
+			},
+		},
+	}
+}
+
+func joinSchemaPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/m-mdy-m/psx/internal/logger"
+	"github.com/m-mdy-m/psx/internal/utils"
+)
+
+// directories never searched for nested config files
+var skipScopeDirs = map[string]bool{
+	".git":         true,
+	".psx":         true,
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// loadScopes finds directories below dir that have their own config file.
+// Like .editorconfig, settings cascade: each nested file is merged on top of
+// the config of the closest directory above it. Every such directory is
+// checked as its own sub-project, with paths relative to it, against the
+// rules its own file sets; the rest are checked once, for the project
+func loadScopes(root, dir string, parentRaw map[string]any, parentSources map[string]string, ignore []string, state *loadState) ([]*Config, error) {
+	nested, err := findNestedConfigs(root, dir, ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := []*Config{}
+	for _, file := range nested {
+		scopeDir := filepath.Dir(file)
+
+		raw, sources, err := readConfigTree(file, root, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config from %s: %w", file, err)
+		}
+
+		merged, mergedSources := overlayConfig(parentRaw, parentSources, raw, sources)
+
+		scope, err := loadMerged(merged, mergedSources, file, scopeDir, state)
+		if err != nil {
+			return nil, err
+		}
+		scope.Scope = displayPath(scopeDir, root)
+		scope.ActiveRules = ownRules(scope.ActiveRules, raw, scope.Profile)
+		logger.Verbose(fmt.Sprintf("Loaded nested config for %s/: %s", scope.Scope, file))
+
+		scope.Scopes, err = loadScopes(root, scopeDir, merged, mergedSources, scope.Ignore, state)
+		if err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, scope)
+	}
+
+	return scopes, nil
+}
+
+// ownRules keeps the active rules that raw, a nested config with what it
+// extends, sets itself: in rules, in the selected profile or as custom
+// rules and plugins. Inherited rules such as readme or license are about
+// the project, and checking them again in every scope would make a nested
+// config stricter than its parent
+func ownRules(active map[string]*ActiveRule, raw map[string]any, profile string) map[string]*ActiveRule {
+	own := map[string]bool{}
+	rules, _ := raw[rulesKey].(map[string]any)
+	profileRules, _ := lookupRaw(raw, "profiles."+profile+"."+rulesKey).(map[string]any)
+	for _, set := range []map[string]any{rules, profileRules} {
+		for id := range set {
+			own[id] = true
+		}
+	}
+	for _, list := range []string{"custom.rules", "custom.plugins"} {
+		items, _ := lookupRaw(raw, list).([]any)
+		for _, item := range items {
+			entry, _ := item.(map[string]any)
+			for _, field := range []string{"id", "name"} {
+				if id, ok := entry[field].(string); ok {
+					own[id] = true
+				}
+			}
+		}
+	}
+
+	result := make(map[string]*ActiveRule, len(own))
+	for id, rule := range active {
+		if own[id] {
+			result[id] = rule
+		}
+	}
+	return result
+}
+
+// overlayConfig merges a nested config onto its parent's, along with the
+// record of which file set what
+func overlayConfig(parentRaw map[string]any, parentSources map[string]string, raw map[string]any, sources map[string]string) (map[string]any, map[string]string) {
+	merged := mergeConfigMaps(parentRaw, raw)
+	mergedSources := make(map[string]string, len(parentSources)+len(sources))
+	for key, value := range parentSources {
+		mergedSources[key] = value
+	}
+	for key, value := range sources {
+		existing := mergedSources[key]
+		if _, isList := lookupRaw(raw, key).([]any); isList && existing != "" && existing != value {
+			value = existing + ", " + value
+		}
+		mergedSources[key] = value
+	}
+	return merged, mergedSources
+}
+
+// lookupRaw returns the value at a dotted path in a raw config
+func lookupRaw(raw map[string]any, key string) any {
+	var value any = raw
+	for _, part := range strings.Split(key, ".") {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = m[part]
+	}
+	return value
+}
+
+// findNestedConfigs returns the config files in the closest directories
+// below dir that have one. Deeper configs are found by the recursive
+// loadScopes call for their parent scope
+func findNestedConfigs(root, dir string, ignore []string) ([]string, error) {
+	found := []string{}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path == dir {
+			return nil
+		}
+		if skipScopeDirs[d.Name()] || isIgnoredDir(displayPath(path, root), ignore) {
+			return filepath.SkipDir
+		}
+
+		file, err := findConfigIn(path)
+		if err != nil {
+			return err
+		}
+		if file != "" {
+			found = append(found, file)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+
+	sort.Strings(found)
+	return found, err
+}
+
+func isIgnoredDir(rel string, ignore []string) bool {
+	for _, pattern := range ignore {
+		pattern = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(pattern), "/"), "/")
+		if pattern == "" {
+			continue
+		}
+		if utils.MatchGlob(pattern, rel) || utils.MatchGlob(pattern, filepath.Base(rel)) {
+			return true
+		}
+	}
+	return false
+}
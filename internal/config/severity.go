@@ -1,7 +1,9 @@
 package config
 
 import (
-	"github.com/m-mdy-m/psx/internal/logger"
+	"fmt"
+	"sort"
+	"time"
 )
 
 const (
@@ -10,29 +12,140 @@ const (
 	SeverityInfo    Severity = "info"
 )
 
+// ScheduleNow marks the step of a severity schedule that applies from the start
+const ScheduleNow = "now"
+
+var asOf time.Time
+
+// SetNow pins the date that severity schedules and suppression expiry are
+// resolved against. The zero time means the real clock
+func SetNow(t time.Time) {
+	asOf = t
+}
+
+func Now() time.Time {
+	if asOf.IsZero() {
+		return time.Now()
+	}
+	return asOf
+}
+
 func ParseSeverity(val any, defaultSev Severity) (*Severity, error) {
 	if b, ok := val.(bool); ok {
 		if !b {
 			return nil, nil
 		}
 
-		return nil, logger.Errorf("invalid value 'true' - use 'error','warning' or 'info'")
+		return nil, fmt.Errorf("invalid value 'true' - use 'error','warning' or 'info'")
 	}
 
 	if s, ok := val.(string); ok {
 		sev := Severity(s)
 		if !sev.IsValid() {
-			return nil, logger.Errorf("invalid severity '%s' - valid values: error,warning,info or false to disable", s)
+			return nil, fmt.Errorf("invalid severity '%s'%s - valid values: error,warning,info or false to disable",
+				s, didYouMean(s, severityNames()))
 		}
 		return &sev, nil
 	}
 
+	if schedule, ok := val.(map[string]any); ok {
+		steps, err := parseSchedule(schedule)
+		if err != nil {
+			return nil, err
+		}
+		// a rule whose first step is still in the future is not active yet
+		var current *Severity
+		now := Now()
+		for i := range steps {
+			if !now.Before(steps[i].from) {
+				current = &steps[i].severity
+			}
+		}
+		return current, nil
+	}
+
 	if val == nil {
 		return &defaultSev, nil
 	}
 
-	return nil, logger.Errorf("invalid type - must be sting ('error','warning','info') or false to disable")
+	return nil, fmt.Errorf("invalid type - must be sting ('error','warning','info'), a schedule or false to disable")
+}
+
+// NextEscalation returns the next step of a severity schedule, or nil when
+// val isn't a schedule or has no steps left
+func NextEscalation(val any) *Escalation {
+	schedule, ok := val.(map[string]any)
+	if !ok {
+		return nil
+	}
+	steps, err := parseSchedule(schedule)
+	if err != nil {
+		return nil
+	}
+	now := Now()
+	for _, step := range steps {
+		if now.Before(step.from) {
+			return &Escalation{Severity: step.severity, Date: step.from}
+		}
+	}
+	return nil
+}
+
+type scheduleStep struct {
+	severity Severity
+	from     time.Time
 }
+
+// parseSchedule reads {warning: now, error: 2027-01-01} into steps sorted
+// by date
+func parseSchedule(schedule map[string]any) ([]scheduleStep, error) {
+	if len(schedule) == 0 {
+		return nil, fmt.Errorf("severity schedule is empty")
+	}
+
+	steps := make([]scheduleStep, 0, len(schedule))
+	dates := map[time.Time]Severity{}
+	for key, value := range schedule {
+		sev := Severity(key)
+		if !sev.IsValid() {
+			return nil, fmt.Errorf("invalid severity '%s' in schedule%s - valid values: error,warning,info",
+				key, didYouMean(key, severityNames()))
+		}
+		from, err := parseScheduleDate(value)
+		if err != nil {
+			return nil, fmt.Errorf("schedule step '%s': %w", key, err)
+		}
+		if other, exists := dates[from]; exists {
+			return nil, fmt.Errorf("'%s' and '%s' start on the same date", other, sev)
+		}
+		dates[from] = sev
+		steps = append(steps, scheduleStep{severity: sev, from: from})
+	}
+
+	sort.Slice(steps, func(i, j int) bool { return steps[i].from.Before(steps[j].from) })
+	return steps, nil
+}
+
+func parseScheduleDate(value any) (time.Time, error) {
+	switch v := value.(type) {
+	case string:
+		if v == ScheduleNow {
+			return time.Time{}, nil
+		}
+		t, err := time.ParseInLocation(time.DateOnly, v, time.Local)
+		if err == nil {
+			return t, nil
+		}
+	case time.Time:
+		return time.Date(v.Year(), v.Month(), v.Day(), 0, 0, 0, 0, time.Local), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date '%v' - use 'now' or YYYY-MM-DD", value)
+}
+
+func severityNames() []string {
+	return []string{string(SeverityError), string(SeverityWarning), string(SeverityInfo)}
+}
+
 func (s Severity) IsValid() bool {
 	switch s {
 	case SeverityError, SeverityWarning, SeverityInfo:
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/m-mdy-m/psx/internal/utils"
+)
+
+// unknownKeys reports keys in raw that psx doesn't read. The YAML decoder
+// silently drops them, and they are almost always typos
+func unknownKeys(raw map[string]any) []ValidationError {
+	return checkKeys(raw, reflect.TypeOf(Config{}), "")
+}
+
+func checkKeys(value any, t reflect.Type, path string) []ValidationError {
+	errors := []ValidationError{}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return checkKeys(value, t.Elem(), path)
+	case reflect.Struct:
+		m, ok := value.(map[string]any)
+		if !ok {
+			return errors // wrong types are reported when decoding
+		}
+		fields := yamlFields(t)
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+
+		for _, key := range sortedKeys(m) {
+			field := joinSchemaPath(path, key)
+			fieldType, known := fields[key]
+			if !known {
+				errors = append(errors, ValidationError{
+					Field:   field,
+					Message: fmt.Sprintf("unknown key '%s'%s", key, didYouMean(key, names)),
+				})
+				continue
+			}
+			errors = append(errors, checkKeys(m[key], fieldType, field)...)
+		}
+	case reflect.Map:
+		// rule IDs are checked by ValidateRules
+		if t.Elem() == rulesSeverityType {
+			return errors
+		}
+		if m, ok := value.(map[string]any); ok {
+			for _, key := range sortedKeys(m) {
+				errors = append(errors, checkKeys(m[key], t.Elem(), joinSchemaPath(path, key))...)
+			}
+		}
+	case reflect.Slice:
+		if list, ok := value.([]any); ok {
+			for i, item := range list {
+				errors = append(errors, checkKeys(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	}
+
+	return errors
+}
+
+// yamlFields maps the keys of a config struct to their types
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if name != "" && name != "-" {
+			fields[name] = t.Field(i).Type
+		}
+	}
+	// extends is resolved before decoding but still a valid key
+	if t == reflect.TypeOf(Config{}) {
+		fields["extends"] = extendsListType
+	}
+	return fields
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// didYouMean formats a suggestion for a misspelt name, or "" when nothing
+// known is close
+func didYouMean(name string, known []string) string {
+	if suggestion := utils.Suggest(name, known); suggestion != "" {
+		return fmt.Sprintf(" - did you mean '%s'?", suggestion)
+	}
+	return ""
+}
+
+// ruleIDs lists the built-in rule IDs
+func ruleIDs() []string {
+	ids := make([]string, 0, len(rulesMetadata.Rules))
+	for id := range rulesMetadata.Rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/m-mdy-m/psx/internal/utils"
+)
+
+// Matches reports whether the suppression covers a finding of rule at
+// target, the finding's path relative to the project root ("" for findings
+// about the project as a whole)
+func (s Suppression) Matches(rule, target string) bool {
+	if s.Rule != rule {
+		return false
+	}
+	if s.Path == "" {
+		return true
+	}
+	if target == "" {
+		return false
+	}
+
+	pattern := strings.Trim(path.Clean(strings.ReplaceAll(s.Path, "\\", "/")), "/")
+	return target == pattern ||
+		strings.HasPrefix(target, pattern+"/") ||
+		utils.MatchGlob(pattern, target)
+}
+
+// Expired reports whether now is past the expiry date. A suppression is
+// still honoured on the day it expires
+func (s Suppression) Expired(now time.Time) bool {
+	if s.Expires == "" {
+		return false
+	}
+	expires, err := time.ParseInLocation(time.DateOnly, s.Expires, now.Location())
+	if err != nil {
+		return false
+	}
+	return !now.Before(expires.AddDate(0, 0, 1))
+}
+
+func (s Suppression) String() string {
+	if s.Path == "" {
+		return s.Rule
+	}
+	return fmt.Sprintf("%s (%s)", s.Rule, s.Path)
+}
+
+func validateSuppressions(c *Config) ([]ValidationError, []ValidationError) {
+	errors := []ValidationError{}
+	warnings := []ValidationError{}
+
+	known := map[string]bool{}
+	for id := range GetRulesMetadata().Rules {
+		known[id] = true
+	}
+	if c.Custom != nil {
+		for _, rule := range c.Custom.Rules {
+			known[rule.ID] = true
+		}
+		for _, plugin := range c.Custom.Plugins {
+			known[plugin.Name] = true
+		}
+	}
+
+	for i, s := range c.Suppressions {
+		field := fmt.Sprintf("suppressions[%d]", i)
+
+		if strings.TrimSpace(s.Rule) == "" {
+			errors = append(errors, ValidationError{Field: field + ".rule", Message: "rule is required"})
+		} else if !known[s.Rule] {
+			ids := make([]string, 0, len(known))
+			for id := range known {
+				ids = append(ids, id)
+			}
+			warnings = append(warnings, ValidationError{
+				Field:   field + ".rule",
+				Message: fmt.Sprintf("%s: unknown rule '%s' - suppression will never match%s", field, s.Rule, didYouMean(s.Rule, ids)),
+			})
+		}
+
+		if strings.TrimSpace(s.Reason) == "" {
+			errors = append(errors, ValidationError{
+				Field:   field + ".reason",
+				Message: "reason is required - say why this rule doesn't apply",
+			})
+		}
+
+		if s.Expires != "" {
+			if _, err := time.Parse(time.DateOnly, s.Expires); err != nil {
+				errors = append(errors, ValidationError{
+					Field:   field + ".expires",
+					Message: fmt.Sprintf("invalid date '%s' - use YYYY-MM-DD", s.Expires),
+				})
+			}
+		}
+	}
+
+	return errors, warnings
+}
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type ValidationError struct {
 	Field   string
 	Message string
@@ -7,14 +9,21 @@ type ValidationError struct {
 type ValidationResult struct {
 	Valid    bool
 	Errors   []ValidationError
-	Warnings []string
+	Warnings []ValidationError
 }
 
 // rules structre
-// can be: "error","warning","info", or false (disbled)
+// can be: "error","warning","info", false (disbled), or a schedule such as
+// {warning: now, error: 2027-01-01}
 type Severity string
 type RulesSeverity any
 
+// Escalation is the next step of a severity schedule
+type Escalation struct {
+	Severity Severity
+	Date     time.Time
+}
+
 type ProjectType struct {
 	Type string `yaml:"type"`
 }
@@ -28,9 +37,18 @@ type ActiveRule struct {
 	ID       string
 	Metadata RuleMetadata
 	Severity Severity
+	Custom   *CustomRule
+	Plugin   *PluginConfig
+	Source   string // config file that decided the severity
+
+	Escalation *Escalation // next scheduled severity change, if any
 }
 
+// can be: "psx:recommended", "../org/psx-base.yml" or a list of both
+type ExtendsList any
+
 type Config struct {
+	Extends ExtendsList              `yaml:"extends,omitempty"`
 	Version int                      `yaml:"version"`
 	Project ProjectType              `yaml:"project"`
 	Rules   map[string]RulesSeverity `yaml:"rules"`
@@ -38,8 +56,19 @@ type Config struct {
 	Fix     FixConfig                `yaml:"fix,omitempty"`
 	Custom  *CustomConfig            `yaml:"custom,omitempty"`
 
+	Suppressions []Suppression      `yaml:"suppressions,omitempty"`
+	Profiles     map[string]Profile `yaml:"profiles,omitempty"`
+	Answers      map[string]string  `yaml:"answers,omitempty"` // question key -> answer for psx fix
+
 	// not in yml file
 	Path        string                 `yaml:"-"`
+	File        string                 `yaml:"-"` // "" when running on defaults
+	Scope       string                 `yaml:"-"` // directory relative to the project root, "" for the root
+	Profile     string                 `yaml:"-"` // selected profile, "" for none
+	FailOn      string                 `yaml:"-"` // check --fail-on default from the profile or an override
+	Output      string                 `yaml:"-"` // check --output default from the profile or an override
+	Sources     map[string]string      `yaml:"-"` // setting (e.g. "rules.readme") -> file that set it
+	Scopes      []*Config              `yaml:"-"` // nested directories with their own config file
 	ActiveRules map[string]*ActiveRule `yaml:"-"`
 }
 
@@ -78,6 +107,11 @@ type RuleConfig struct {
 type CustomConfig struct {
 	Files   []CustomFile   `yaml:"files"`
 	Folders []CustomFolder `yaml:"folders"`
+	Rules   []CustomRule   `yaml:"rules,omitempty"`
+	Plugins []PluginConfig `yaml:"plugins,omitempty"`
+
+	// Concurrency caps how many command rules run at once (default: CPU count)
+	Concurrency int `yaml:"concurrency,omitempty"`
 }
 type CustomFile struct {
 	Path    string `yaml:"path"`
@@ -87,3 +121,55 @@ type CustomFolder struct {
 	Path      string                 `yaml:"path"`
 	Structure map[string]interface{} `yaml:"structure"`
 }
+
+// CustomRule is a project-specific rule whose check is a Starlark script
+// or a local command instead of a list of patterns
+type CustomRule struct {
+	ID          string        `yaml:"id"`
+	Description string        `yaml:"description,omitempty"`
+	Severity    RulesSeverity `yaml:"severity,omitempty"`
+	Message     string        `yaml:"message,omitempty"`
+	FixHint     string        `yaml:"fix_hint,omitempty"`
+	Script      string        `yaml:"script,omitempty"`
+	ScriptFile  string        `yaml:"script_file,omitempty"`
+
+	// Command is a shell command string, or a map of project type to
+	// command with "*" as the fallback. Exit code 0 passes
+	Command CommandVariants   `yaml:"command,omitempty"`
+	Timeout string            `yaml:"timeout,omitempty"`
+	WorkDir string            `yaml:"workdir,omitempty"`
+	Env     map[string]string `yaml:"env,omitempty"`
+}
+
+// can be: "go mod verify" or {go: "go mod verify", "*": "make check"}
+type CommandVariants any
+
+// PluginConfig enables an external rule executable that speaks the psx
+// plugin protocol over stdin/stdout. Path is optional: without it the
+// plugin is looked up in .psx/plugins/ and then as psx-rule-<name> on $PATH
+type PluginConfig struct {
+	Name     string         `yaml:"name"`
+	Path     string         `yaml:"path,omitempty"`
+	Severity RulesSeverity  `yaml:"severity,omitempty"`
+	Timeout  string         `yaml:"timeout,omitempty"`
+	Config   map[string]any `yaml:"config,omitempty"`
+}
+
+// Profile adjusts the config for one environment (e.g. local, ci, release).
+// Its rules are merged over the top-level rules; FailOn and Output are the
+// defaults for the matching check flags
+type Profile struct {
+	Rules  map[string]RulesSeverity `yaml:"rules,omitempty"`
+	FailOn string                   `yaml:"fail_on,omitempty"`
+	Output string                   `yaml:"output,omitempty"`
+}
+
+// Suppression accepts a known violation of a rule. Path limits it to
+// findings under a file or directory (relative to the project root, globs
+// allowed). After Expires (YYYY-MM-DD) the suppression stops applying
+type Suppression struct {
+	Rule    string `yaml:"rule"`
+	Path    string `yaml:"path,omitempty"`
+	Reason  string `yaml:"reason"`
+	Expires string `yaml:"expires,omitempty"`
+}
@@ -2,9 +2,14 @@ package config
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
+	"time"
+
+	"go.starlark.net/syntax"
 
 	"github.com/m-mdy-m/psx/internal/resources"
+	"github.com/m-mdy-m/psx/internal/utils"
 )
 
 func IsValid(r ValidationResult) bool {
@@ -19,11 +24,16 @@ func Validate(c *Config) ValidationResult {
 	result := ValidationResult{
 		Valid:    true,
 		Errors:   []ValidationError{},
-		Warnings: []string{},
+		Warnings: []ValidationError{},
 	}
 	if err := ValidateVersion(c.Version); err != nil {
 		result.Errors = append(result.Errors, *err)
 		result.Valid = false
+	} else if c.Version != 0 && c.Version < CurrentVersion {
+		result.Warnings = append(result.Warnings, ValidationError{
+			Field:   "version",
+			Message: fmt.Sprintf("config version %d is out of date (current: %d) - run 'psx config migrate'", c.Version, CurrentVersion),
+		})
 	}
 
 	if warnings := ValidateProjectType(c.Project.Type); len(warnings) > 0 {
@@ -42,8 +52,63 @@ func Validate(c *Config) ValidationResult {
 		result.Warnings = append(result.Warnings, warns...)
 	}
 
+	if errs, warns := validateProfiles(c.Profiles); len(errs) > 0 || len(warns) > 0 {
+		result.Errors = append(result.Errors, errs...)
+		result.Warnings = append(result.Warnings, warns...)
+		if len(errs) > 0 {
+			result.Valid = false
+		}
+	}
+
+	if errs := validateAnswers(c.Answers); len(errs) > 0 {
+		result.Errors = append(result.Errors, errs...)
+		result.Valid = false
+	}
+
+	if errs, warns := validateSuppressions(c); len(errs) > 0 || len(warns) > 0 {
+		result.Errors = append(result.Errors, errs...)
+		result.Warnings = append(result.Warnings, warns...)
+		if len(errs) > 0 {
+			result.Valid = false
+		}
+	}
+
+	if c.Custom != nil {
+		if errs := validateCustomRules(c.Custom.Rules); len(errs) > 0 {
+			result.Errors = append(result.Errors, errs...)
+			result.Valid = false
+		}
+		if errs := validatePlugins(c.Custom.Plugins, c.Custom.Rules); len(errs) > 0 {
+			result.Errors = append(result.Errors, errs...)
+			result.Valid = false
+		}
+		if c.Custom.Concurrency < 0 {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "custom.concurrency",
+				Message: "concurrency must be >= 0",
+			})
+			result.Valid = false
+		}
+	}
+
 	return result
 }
+
+// validateAnswers checks answers are for questions psx asks, with one of
+// the allowed values
+func validateAnswers(answers map[string]string) []ValidationError {
+	errors := []ValidationError{}
+	for key, value := range answers {
+		if err := utils.ValidateAnswer(key, value); err != nil {
+			errors = append(errors, ValidationError{
+				Field:   "answers." + key,
+				Message: err.Error(),
+			})
+		}
+	}
+	return errors
+}
+
 func ValidateVersion(version int) *ValidationError {
 	if version < 0 {
 		return &ValidationError{
@@ -51,11 +116,17 @@ func ValidateVersion(version int) *ValidationError {
 			Message: "version must be >=0",
 		}
 	}
+	if version > CurrentVersion {
+		return &ValidationError{
+			Field:   "version",
+			Message: fmt.Sprintf("version %d is newer than this psx supports (%d) - upgrade psx", version, CurrentVersion),
+		}
+	}
 	return nil
 }
 
-func ValidateProjectType(pt string) []string {
-	warnings := []string{}
+func ValidateProjectType(pt string) []ValidationError {
+	warnings := []ValidationError{}
 
 	if pt == "" {
 		return warnings
@@ -63,19 +134,19 @@ func ValidateProjectType(pt string) []string {
 	normalized := resources.NormalizeProjectType(pt)
 
 	if normalized == "generic" {
-		warnings = append(warnings, fmt.Sprintf(
-			"project.type '%s' is not a known type. Will use generic rules.",
-			pt,
-		))
+		warnings = append(warnings, ValidationError{
+			Field:   "project.type",
+			Message: fmt.Sprintf("project.type '%s' is not a known type. Will use generic rules.", pt),
+		})
 		return warnings
 	}
 	return warnings
 }
-func ValidateRules(rules map[string]RulesSeverity) ([]ValidationError, []string) {
+func ValidateRules(rules map[string]RulesSeverity) ([]ValidationError, []ValidationError) {
 	errors := []ValidationError{}
-	warnings := []string{}
+	warnings := []ValidationError{}
 	if len(rules) == 0 {
-		warnings = append(warnings, "No rules configured")
+		warnings = append(warnings, ValidationError{Field: "rules", Message: "No rules configured"})
 		return errors, warnings
 	}
 
@@ -84,8 +155,10 @@ func ValidateRules(rules map[string]RulesSeverity) ([]ValidationError, []string)
 	for id, severtity := range rules {
 		ruleMeta, exists := metadata.Rules[id]
 		if !exists {
-			warnings = append(warnings, fmt.Sprintf(
-				"Unknown rule '%s' - will be ignored", id))
+			errors = append(errors, ValidationError{
+				Field:   fmt.Sprintf("rules.%s", id),
+				Message: fmt.Sprintf("unknown rule '%s'%s", id, didYouMean(id, ruleIDs())),
+			})
 			continue
 		}
 		if err := validateRuleSeverity(id, severtity, ruleMeta.DefaultSeverity); err != nil {
@@ -97,7 +170,10 @@ func ValidateRules(rules map[string]RulesSeverity) ([]ValidationError, []string)
 	for _, id := range criticalRules {
 		if sev, exists := rules[id]; exists {
 			if b, ok := sev.(bool); ok && !b {
-				warnings = append(warnings, fmt.Sprintf("Critical rule '%s' is disabled - this is not recommended", id))
+				warnings = append(warnings, ValidationError{
+					Field:   fmt.Sprintf("rules.%s", id),
+					Message: fmt.Sprintf("Critical rule '%s' is disabled - this is not recommended", id),
+				})
 			}
 		}
 	}
@@ -115,8 +191,8 @@ func validateRuleSeverity(id string, severity RulesSeverity, defaultSev Severity
 	return nil
 }
 
-func validateIgnorePatterns(patterns []string) []string {
-	warnings := []string{}
+func validateIgnorePatterns(patterns []string) []ValidationError {
+	warnings := []ValidationError{}
 
 	if len(patterns) == 0 {
 		// No patterns is OK, just informational
@@ -124,29 +200,175 @@ func validateIgnorePatterns(patterns []string) []string {
 	}
 
 	for i, pattern := range patterns {
+		field := fmt.Sprintf("ignore[%d]", i)
+
 		if strings.TrimSpace(pattern) == "" {
-			warnings = append(warnings, fmt.Sprintf(
-				"ignore[%d] is empty",
-				i,
-			))
+			warnings = append(warnings, ValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("%s is empty", field),
+			})
 			continue
 		}
 
 		if pattern == "*" || pattern == "**" {
-			warnings = append(warnings, fmt.Sprintf(
-				"ignore[%d]: pattern '%s' will ignore everything - is this intentional?",
-				i, pattern,
-			))
+			warnings = append(warnings, ValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("%s: pattern '%s' will ignore everything - is this intentional?", field, pattern),
+			})
 		}
 
 		// Warn about absolute paths
 		if strings.HasPrefix(pattern, "/") {
-			warnings = append(warnings, fmt.Sprintf(
-				"ignore[%d]: absolute path '%s' - relative paths are recommended",
-				i, pattern,
-			))
+			warnings = append(warnings, ValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("%s: absolute path '%s' - relative paths are recommended", field, pattern),
+			})
 		}
 	}
 
 	return warnings
 }
+
+func validateCustomRules(customRules []CustomRule) []ValidationError {
+	errors := []ValidationError{}
+	seen := map[string]bool{}
+	metadata := GetRulesMetadata()
+
+	for i, rule := range customRules {
+		field := fmt.Sprintf("custom.rules[%d]", i)
+
+		if strings.TrimSpace(rule.ID) == "" {
+			errors = append(errors, ValidationError{Field: field + ".id", Message: "id is required"})
+			continue
+		}
+		if _, exists := metadata.Rules[rule.ID]; exists {
+			errors = append(errors, ValidationError{
+				Field:   field + ".id",
+				Message: fmt.Sprintf("'%s' is a built-in rule - custom rules need their own id", rule.ID),
+			})
+		}
+		if seen[rule.ID] {
+			errors = append(errors, ValidationError{
+				Field:   field + ".id",
+				Message: fmt.Sprintf("duplicate custom rule id '%s'", rule.ID),
+			})
+		}
+		seen[rule.ID] = true
+
+		if err := validateRuleSeverity(rule.ID, rule.Severity, SeverityWarning); err != nil {
+			err.Field = field + ".severity"
+			errors = append(errors, *err)
+		}
+
+		sources := 0
+		for _, set := range []bool{rule.Script != "", rule.ScriptFile != "", rule.Command != nil} {
+			if set {
+				sources++
+			}
+		}
+
+		switch {
+		case sources > 1:
+			errors = append(errors, ValidationError{Field: field, Message: "use only one of script, script_file or command"})
+		case sources == 0:
+			errors = append(errors, ValidationError{Field: field, Message: "script, script_file or command is required"})
+		case rule.Command != nil:
+			errors = append(errors, validateCommandRule(field, rule)...)
+		case rule.Script != "":
+			if _, err := syntax.Parse(rule.ID+".star", rule.Script, 0); err != nil {
+				errors = append(errors, ValidationError{
+					Field:   field + ".script",
+					Message: fmt.Sprintf("invalid script: %v", err),
+				})
+			}
+		}
+	}
+
+	return errors
+}
+
+func validatePlugins(plugins []PluginConfig, customRules []CustomRule) []ValidationError {
+	errors := []ValidationError{}
+	taken := map[string]bool{}
+	for id := range GetRulesMetadata().Rules {
+		taken[id] = true
+	}
+	for _, rule := range customRules {
+		taken[rule.ID] = true
+	}
+
+	for i, plugin := range plugins {
+		field := fmt.Sprintf("custom.plugins[%d]", i)
+
+		if strings.TrimSpace(plugin.Name) == "" {
+			errors = append(errors, ValidationError{Field: field + ".name", Message: "name is required"})
+			continue
+		}
+		if taken[plugin.Name] {
+			errors = append(errors, ValidationError{
+				Field:   field + ".name",
+				Message: fmt.Sprintf("'%s' is already used by another rule", plugin.Name),
+			})
+		}
+		taken[plugin.Name] = true
+
+		if err := validateRuleSeverity(plugin.Name, plugin.Severity, SeverityWarning); err != nil {
+			err.Field = field + ".severity"
+			errors = append(errors, *err)
+		}
+
+		if plugin.Timeout != "" {
+			if d, err := time.ParseDuration(plugin.Timeout); err != nil || d <= 0 {
+				errors = append(errors, ValidationError{
+					Field:   field + ".timeout",
+					Message: fmt.Sprintf("invalid timeout '%s' - use a duration like 30s", plugin.Timeout),
+				})
+			}
+		}
+	}
+
+	return errors
+}
+
+func validateCommandRule(field string, rule CustomRule) []ValidationError {
+	errors := []ValidationError{}
+
+	switch c := rule.Command.(type) {
+	case string:
+		if strings.TrimSpace(c) == "" {
+			errors = append(errors, ValidationError{Field: field + ".command", Message: "command is empty"})
+		}
+	case map[string]any:
+		for projectType, variant := range c {
+			if s, ok := variant.(string); !ok || strings.TrimSpace(s) == "" {
+				errors = append(errors, ValidationError{
+					Field:   fmt.Sprintf("%s.command.%s", field, projectType),
+					Message: "command variant must be a non-empty string",
+				})
+			}
+		}
+	default:
+		errors = append(errors, ValidationError{
+			Field:   field + ".command",
+			Message: "command must be a string or a map of project type to command",
+		})
+	}
+
+	if rule.Timeout != "" {
+		if d, err := time.ParseDuration(rule.Timeout); err != nil || d <= 0 {
+			errors = append(errors, ValidationError{
+				Field:   field + ".timeout",
+				Message: fmt.Sprintf("invalid timeout '%s' - use a duration like 30s", rule.Timeout),
+			})
+		}
+	}
+
+	if filepath.IsAbs(rule.WorkDir) || strings.HasPrefix(filepath.Clean(rule.WorkDir), "..") {
+		errors = append(errors, ValidationError{
+			Field:   field + ".workdir",
+			Message: "workdir must be relative to the project root",
+		})
+	}
+
+	return errors
+}
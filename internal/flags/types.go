@@ -4,11 +4,14 @@ type GlobalFlags struct {
     Verbose    bool
     Quiet      bool
     NoColor    bool
+    Profile    string
+    Set        []string
 }
 type Check struct {
 	OutputFormat     string
 	ServerityLevel   string
 	FailOn			 string
+	AsOf             string
 }
 
 type Fix struct {
@@ -17,6 +20,19 @@ type Fix struct {
 	RuleID        string
 	All           bool
 	CreateBackups bool
+	Stat          bool
+	NoPager       bool
+	Output        string
+
+	Answers        []string // key=value
+	AnswersFile    string
+	NonInteractive bool
+
+	Branch     string
+	Commit     string // "", "combined" or "per-rule"
+	AllowDirty bool
+
+	Update bool // bring generated files up to date with their templates
 }
 
 type Init struct {
@@ -30,12 +46,30 @@ type Rules struct {
 	JSON     bool
 }
 
+type Config struct {
+	Resolved bool
+	DryRun   bool
+	Yes      bool
+}
+
+type Undo struct {
+	DryRun bool
+	Force  bool
+}
+
+type SelfTest struct {
+	Types []string
+}
+
 type Flags struct {
 	GlobalFlags GlobalFlags
 	Check       Check
 	Fix         Fix
 	Init        Init
 	Rules       Rules
+	Config      Config
+	Undo        Undo
+	SelfTest    SelfTest
 }
 
 var DefaultValues = Flags{
@@ -44,6 +78,8 @@ var DefaultValues = Flags{
 		Verbose:    false,
 		Quiet:      false,
 		NoColor:    false,
+		Profile:    "",
+		Set:        nil,
 	},
 	Check: Check{
 		OutputFormat:   "table",
@@ -56,6 +92,19 @@ var DefaultValues = Flags{
 		RuleID:        "",
 		All:           false,
 		CreateBackups: false,
+		Stat:          false,
+		NoPager:       false,
+		Output:        "text",
+
+		Answers:        nil,
+		AnswersFile:    "",
+		NonInteractive: false,
+
+		Branch:     "",
+		Commit:     "",
+		AllowDirty: false,
+
+		Update: false,
 	},
 	Init: Init{
 		Template: "",
@@ -66,4 +115,16 @@ var DefaultValues = Flags{
 		Category: "",
 		JSON:     false,
 	},
+	Config: Config{
+		Resolved: false,
+		DryRun:   false,
+		Yes:      false,
+	},
+	Undo: Undo{
+		DryRun: false,
+		Force:  false,
+	},
+	SelfTest: SelfTest{
+		Types: nil,
+	},
 }
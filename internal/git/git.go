@@ -0,0 +1,138 @@
+// Package git runs the git commands psx fix needs to put its changes on a
+// branch and commit them
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ErrNotRepo is returned when a directory isn't inside a git work tree
+var ErrNotRepo = errors.New("not a git repository")
+
+// Repo is a git work tree
+type Repo struct {
+	Root string // top-level directory
+}
+
+// Open returns the repository dir is in
+func Open(dir string) (*Repo, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git is not installed")
+	}
+	root, err := (&Repo{Root: dir}).run("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, ErrNotRepo
+	}
+	return &Repo{Root: root}, nil
+}
+
+// Changes lists the paths with uncommitted changes, untracked files
+// included, relative to the root
+func (r *Repo) Changes() ([]string, error) {
+	out, err := r.run("status", "--porcelain", "--untracked-files=normal")
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{}
+	for _, line := range strings.Split(out, "\n") {
+		if len(line) < 4 {
+			continue
+		}
+		path := line[3:]
+		if _, to, renamed := strings.Cut(path, " -> "); renamed {
+			path = to
+		}
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			path = unquoted
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// Head returns the current branch, or the commit when HEAD is detached
+func (r *Repo) Head() (ref string, detached bool, err error) {
+	if branch, err := r.run("symbolic-ref", "--short", "-q", "HEAD"); err == nil {
+		return branch, false, nil
+	}
+	ref, err = r.run("rev-parse", "HEAD")
+	return ref, true, err
+}
+
+// CreateBranch creates name from HEAD and switches to it, keeping the work
+// tree as it is
+func (r *Repo) CreateBranch(name string) error {
+	if _, err := r.run("rev-parse", "--verify", "-q", "refs/heads/"+name); err == nil {
+		return fmt.Errorf("branch '%s' already exists", name)
+	}
+	_, err := r.run("switch", "-c", name)
+	return err
+}
+
+// Switch checks out a branch, or a commit when detached, keeping the work
+// tree as it is
+func (r *Repo) Switch(ref string, detached bool) error {
+	args := []string{"switch", ref}
+	if detached {
+		args = []string{"switch", "--detach", ref}
+	}
+	_, err := r.run(args...)
+	return err
+}
+
+// DeleteBranch removes a branch that isn't checked out
+func (r *Repo) DeleteBranch(name string) error {
+	_, err := r.run("branch", "-D", name)
+	return err
+}
+
+// Identity returns user.name and user.email from git config, which
+// commits are authored with
+func (r *Repo) Identity() (string, string, error) {
+	name, _ := r.run("config", "user.name")
+	email, _ := r.run("config", "user.email")
+	if name == "" || email == "" {
+		return "", "", fmt.Errorf("git user.name and user.email must be set to commit - see 'git config --help'")
+	}
+	return name, email, nil
+}
+
+// Commit stages paths, including deletions, and commits them with message.
+// It returns the short hash of the commit, "" when none of the paths had
+// changes to commit
+func (r *Repo) Commit(message string, paths []string) (string, error) {
+	if len(paths) == 0 {
+		return "", nil
+	}
+	if _, err := r.run(append([]string{"add", "-A", "--"}, paths...)...); err != nil {
+		return "", err
+	}
+	if _, err := r.run(append([]string{"diff", "--cached", "--quiet", "--"}, paths...)...); err == nil {
+		return "", nil
+	}
+	if _, err := r.run(append([]string{"commit", "-q", "-m", message, "--"}, paths...)...); err != nil {
+		return "", err
+	}
+	return r.run("rev-parse", "--short", "HEAD")
+}
+
+// run runs git in the repository and returns its output without the final
+// newline. A failure carries what git wrote to stderr
+func (r *Repo) run(args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", r.Root}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
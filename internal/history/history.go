@@ -0,0 +1,427 @@
+// Package history records what each `psx fix` run changed, under
+// .psx/history/<run-id>/, so the run can be undone
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/m-mdy-m/psx/internal/utils"
+)
+
+// Dir holds one directory per run, relative to the project root
+const Dir = ".psx/history"
+
+// StagingDir holds the new contents of files while a run is applied,
+// relative to the project root
+const StagingDir = ".psx/staging"
+
+const (
+	runFile  = "run.yml"
+	filesDir = "files" // original contents of modified files
+	idFormat = "20060102-150405"
+)
+
+// ErrNoRuns is returned when a project has no fix history to undo
+var ErrNoRuns = errors.New("no fix runs to undo")
+
+// Run is the journal of one fix run
+type Run struct {
+	ID       string     `yaml:"id"`
+	Time     time.Time  `yaml:"time"`
+	Command  string     `yaml:"command"`
+	Created  []Entry    `yaml:"created,omitempty"`  // in creation order
+	Modified []Entry    `yaml:"modified,omitempty"` // originals are in files/
+	Undone   *time.Time `yaml:"undone,omitempty"`
+}
+
+// Entry is a path a run created or modified, relative to the project root
+type Entry struct {
+	Path string      `yaml:"path"`
+	Dir  bool        `yaml:"dir,omitempty"`
+	Mode os.FileMode `yaml:"mode,omitempty"` // of the original file
+	Hash string      `yaml:"hash,omitempty"` // of the content psx wrote
+
+	Deleted bool `yaml:"deleted,omitempty"` // a modified file the run removed
+}
+
+// Changes is the number of paths the run touched
+func (r *Run) Changes() int {
+	return len(r.Created) + len(r.Modified)
+}
+
+// Journal makes the changes of a run and records them
+type Journal struct {
+	root    string
+	backups bool
+	run     Run
+	dir     string
+	touched map[string]bool
+}
+
+// Begin starts the journal of a run in the project at root. With backups,
+// files are also copied to <file>.bak before they are modified
+func Begin(root, command string, backups bool) *Journal {
+	now := time.Now()
+	return &Journal{
+		root:    root,
+		backups: backups,
+		run:     Run{ID: now.Format(idFormat), Time: now, Command: command},
+		touched: map[string]bool{},
+	}
+}
+
+// Write is one planned change: a file with its new content, a folder, or
+// a file to delete
+type Write struct {
+	Path    string
+	Dir     bool
+	Delete  bool
+	Content []byte
+}
+
+// Commit makes writes as one transaction. File contents are staged under
+// .psx/staging first and then renamed into place, so no file is left half
+// written, and when a write fails every write before it is rolled back
+func (j *Journal) Commit(writes []Write) error {
+	staging, err := j.stage(writes)
+	if err != nil {
+		return err
+	}
+	defer j.unstage(staging)
+
+	for i, w := range writes {
+		switch {
+		case w.Dir:
+			err = j.CreateDir(w.Path)
+		case w.Delete:
+			err = j.deleteFile(w.Path)
+		default:
+			err = j.commitFile(w.Path, filepath.Join(staging, strconv.Itoa(i)), w.Content)
+		}
+		if err == nil {
+			continue
+		}
+		if rerr := j.rollback(); rerr != nil {
+			return fmt.Errorf("%s: %w - rolling back earlier changes also failed: %v", j.rel(w.Path), err, rerr)
+		}
+		return fmt.Errorf("%s: %w - all changes of this run were rolled back", j.rel(w.Path), err)
+	}
+	return nil
+}
+
+// stage writes the content of every file to a fresh staging folder inside
+// the project, on the same file system as the files it replaces
+func (j *Journal) stage(writes []Write) (string, error) {
+	base := filepath.Join(j.root, filepath.FromSlash(StagingDir))
+	if err := os.MkdirAll(base, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", StagingDir, err)
+	}
+	staging, err := os.MkdirTemp(base, j.run.ID+"-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", StagingDir, err)
+	}
+	for i, w := range writes {
+		if w.Dir || w.Delete {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(staging, strconv.Itoa(i)), w.Content, 0644); err != nil {
+			j.unstage(staging)
+			return "", fmt.Errorf("failed to stage %s: %w", j.rel(w.Path), err)
+		}
+	}
+	return staging, nil
+}
+
+// unstage removes a staging folder, and .psx/staging and .psx once they
+// are empty
+func (j *Journal) unstage(staging string) {
+	os.RemoveAll(staging)
+	os.Remove(filepath.Dir(staging))
+	os.Remove(filepath.Dir(filepath.Dir(staging)))
+}
+
+// commitFile renames the staged content over path, recording the original
+// of an existing file or that the file (and any parent folders) are new
+func (j *Journal) commitFile(path, staged string, content []byte) error {
+	mode := utils.NewFileMode(content)
+	original, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		mode = info.Mode().Perm()
+		if err := j.recordModified(path, original, mode); err != nil {
+			return err
+		}
+	case errors.Is(err, os.ErrNotExist):
+		if err := j.CreateDir(filepath.Dir(path)); err != nil {
+			return err
+		}
+		j.recordCreated(path, false)
+	default:
+		return err
+	}
+
+	if err := os.Chmod(staged, mode); err != nil {
+		return err
+	}
+	if err := os.Rename(staged, path); err != nil {
+		return err
+	}
+	j.setHash(path, content)
+	return nil
+}
+
+// deleteFile removes path, keeping its original like a modified file
+func (j *Journal) deleteFile(path string) error {
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := j.recordModified(path, original, info.Mode().Perm()); err != nil {
+		return err
+	}
+	j.run.Modified[len(j.run.Modified)-1].Deleted = true
+	return os.Remove(path)
+}
+
+// rollback undoes what the run has committed so far and forgets it, so
+// nothing of the run is left in the project or its history
+func (j *Journal) rollback() error {
+	var errs []error
+	for i := len(j.run.Modified) - 1; i >= 0; i-- {
+		entry := j.run.Modified[i]
+		path := projectPath(j.root, entry.Path)
+		original, err := os.ReadFile(filepath.Join(j.dir, filesDir, filepath.FromSlash(entry.Path)))
+		if err == nil {
+			err = os.WriteFile(path, original, entry.Mode)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if j.backups {
+			os.Remove(path + ".bak")
+		}
+	}
+	for i := len(j.run.Created) - 1; i >= 0; i-- {
+		err := os.Remove(projectPath(j.root, j.run.Created[i].Path))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if j.dir != "" {
+		os.RemoveAll(j.dir)
+	}
+	j.run.Created, j.run.Modified = nil, nil
+	j.touched = map[string]bool{}
+	j.dir = ""
+	return nil
+}
+
+// CreateDir creates path and its missing parents, recording each of them
+func (j *Journal) CreateDir(path string) error {
+	missing := []string{}
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil || dir == filepath.Dir(dir) {
+			break
+		}
+		missing = append(missing, dir)
+	}
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return err
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		j.recordCreated(missing[i], true)
+	}
+	return nil
+}
+
+// Close writes the journal and returns the run, nil when nothing changed
+func (j *Journal) Close() (*Run, error) {
+	if j == nil || j.run.Changes() == 0 {
+		return nil, nil
+	}
+	if err := j.ensureDir(); err != nil {
+		return nil, err
+	}
+	if err := writeRun(j.dir, &j.run); err != nil {
+		return nil, err
+	}
+	return &j.run, nil
+}
+
+func (j *Journal) recordModified(path string, original []byte, mode os.FileMode) error {
+	rel := j.rel(path)
+	if j.touched[rel] {
+		return nil
+	}
+	j.touched[rel] = true
+
+	if err := j.ensureDir(); err != nil {
+		return err
+	}
+	saved := filepath.Join(j.dir, filesDir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(saved), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(saved, original, 0644); err != nil {
+		return fmt.Errorf("failed to save original of %s: %w", rel, err)
+	}
+	if j.backups {
+		if err := os.WriteFile(path+".bak", original, mode); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", rel, err)
+		}
+	}
+	j.run.Modified = append(j.run.Modified, Entry{Path: rel, Mode: mode})
+	return nil
+}
+
+func (j *Journal) recordCreated(path string, dir bool) {
+	rel := j.rel(path)
+	if j.touched[rel] {
+		return
+	}
+	j.touched[rel] = true
+	j.run.Created = append(j.run.Created, Entry{Path: rel, Dir: dir})
+}
+
+func (j *Journal) setHash(path string, content []byte) {
+	rel := j.rel(path)
+	for _, entries := range [][]Entry{j.run.Created, j.run.Modified} {
+		for i := range entries {
+			if entries[i].Path == rel {
+				entries[i].Hash = hash(content)
+			}
+		}
+	}
+}
+
+// ensureDir creates the run's directory, picking a free ID when runs start
+// within the same second
+func (j *Journal) ensureDir() error {
+	if j.dir != "" {
+		return nil
+	}
+	base := filepath.Join(j.root, filepath.FromSlash(Dir))
+	if err := os.MkdirAll(base, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", Dir, err)
+	}
+	id := j.run.ID
+	for n := 2; ; n++ {
+		err := os.Mkdir(filepath.Join(base, id), 0755)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("failed to create %s: %w", Dir, err)
+		}
+		id = fmt.Sprintf("%s-%d", j.run.ID, n)
+	}
+	j.run.ID = id
+	j.dir = filepath.Join(base, id)
+	return nil
+}
+
+// rel returns path relative to the project root, slash-separated
+func (j *Journal) rel(path string) string {
+	rel, err := filepath.Rel(j.root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Runs lists the recorded runs of the project at root, oldest first
+func Runs(root string) ([]*Run, error) {
+	entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(Dir)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	runs := []*Run{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		run, err := readRun(root, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(a, b int) bool { return runs[a].Time.Before(runs[b].Time) })
+	return runs, nil
+}
+
+// Latest returns the newest run that hasn't been undone
+func Latest(root string) (*Run, error) {
+	runs, err := Runs(root)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(runs) - 1; i >= 0; i-- {
+		if runs[i].Undone == nil {
+			return runs[i], nil
+		}
+	}
+	return nil, ErrNoRuns
+}
+
+// Find returns the run with the given ID
+func Find(root, id string) (*Run, error) {
+	run, err := readRun(root, id)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no fix run '%s' - see 'psx history fixes'", id)
+	}
+	return run, err
+}
+
+func readRun(root, id string) (*Run, error) {
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(Dir), id, runFile))
+	if err != nil {
+		return nil, err
+	}
+	run := &Run{}
+	if err := yaml.Unmarshal(data, run); err != nil {
+		return nil, fmt.Errorf("%s/%s/%s: %w", Dir, id, runFile, err)
+	}
+	run.ID = id
+	return run, nil
+}
+
+func writeRun(dir string, run *Run) error {
+	data, err := yaml.Marshal(run)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, runFile), data, 0644)
+}
+
+func hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,130 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Undo is what undoing a run does, or would do
+type Undo struct {
+	Run       *Run
+	Restored  []string // modified files put back
+	Removed   []string // created files and empty folders deleted
+	Kept      []string // created folders that are no longer empty
+	Conflicts []string // files changed since the run
+}
+
+// PlanUndo works out how to undo run without changing anything
+func PlanUndo(root string, run *Run) (*Undo, error) {
+	if run.Undone != nil {
+		return nil, fmt.Errorf("run %s was already undone on %s", run.ID, run.Undone.Format(time.DateTime))
+	}
+
+	u := &Undo{Run: run}
+	for _, entry := range run.Modified {
+		if changedSince(root, entry) {
+			u.Conflicts = append(u.Conflicts, entry.Path)
+		}
+		u.Restored = append(u.Restored, entry.Path)
+	}
+
+	// newest first, so files go before their folders
+	for i := len(run.Created) - 1; i >= 0; i-- {
+		entry := run.Created[i]
+		path := projectPath(root, entry.Path)
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if entry.Dir {
+			if dirKeeps(root, path, run) {
+				u.Kept = append(u.Kept, entry.Path)
+			} else {
+				u.Removed = append(u.Removed, entry.Path)
+			}
+			continue
+		}
+		if changedSince(root, entry) {
+			u.Conflicts = append(u.Conflicts, entry.Path)
+		}
+		u.Removed = append(u.Removed, entry.Path)
+	}
+	return u, nil
+}
+
+// Apply undoes the run. Files changed since the run are only overwritten
+// or deleted with force
+func (u *Undo) Apply(root string, force bool) error {
+	if len(u.Conflicts) > 0 && !force {
+		return fmt.Errorf("changed since run %s: %s - use --force to undo anyway",
+			u.Run.ID, strings.Join(u.Conflicts, ", "))
+	}
+
+	dir := filepath.Join(root, filepath.FromSlash(Dir), u.Run.ID)
+	for _, entry := range u.Run.Modified {
+		original, err := os.ReadFile(filepath.Join(dir, filesDir, filepath.FromSlash(entry.Path)))
+		if err != nil {
+			return fmt.Errorf("original of %s is missing from the journal: %w", entry.Path, err)
+		}
+		mode := entry.Mode
+		if mode == 0 {
+			mode = 0644
+		}
+		if err := os.WriteFile(projectPath(root, entry.Path), original, mode); err != nil {
+			return err
+		}
+	}
+	for _, rel := range u.Removed {
+		if err := os.Remove(projectPath(root, rel)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	now := time.Now()
+	u.Run.Undone = &now
+	return writeRun(dir, u.Run)
+}
+
+// changedSince reports whether the file at entry no longer has the content
+// the run wrote. A file that is gone counts as unchanged, unless the run
+// deleted it and it is back
+func changedSince(root string, entry Entry) bool {
+	if entry.Deleted {
+		_, err := os.Stat(projectPath(root, entry.Path))
+		return err == nil
+	}
+	content, err := os.ReadFile(projectPath(root, entry.Path))
+	if err != nil {
+		return false
+	}
+	return entry.Hash != "" && hash(content) != entry.Hash
+}
+
+// dirKeeps reports whether a created folder holds anything the run didn't
+// create, so it has to stay
+func dirKeeps(root, path string, run *Run) bool {
+	created := map[string]bool{}
+	for _, entry := range run.Created {
+		created[projectPath(root, entry.Path)] = true
+	}
+
+	keep := false
+	filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err == nil && p != path && !created[p] {
+			keep = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return keep
+}
+
+func projectPath(root, rel string) string {
+	if filepath.IsAbs(rel) {
+		return rel
+	}
+	return filepath.Join(root, filepath.FromSlash(rel))
+}
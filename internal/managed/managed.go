@@ -0,0 +1,121 @@
+// Package managed keeps .psx/managed.yml, the record of the files psx
+// generated from its templates, so they can be checked against newer
+// templates and updated without losing local edits
+package managed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/goccy/go-yaml"
+)
+
+// File is the manifest, relative to the project root
+const File = ".psx/managed.yml"
+
+// BaseDir holds the content each file was last generated with, named by
+// its hash, which a three-way merge needs as the common ancestor
+const BaseDir = ".psx/managed"
+
+// DateFormat is how the generation date of an entry is written
+const DateFormat = "2006-01-02"
+
+// Manifest lists the generated files of a project
+type Manifest struct {
+	// answers the files were generated with, so they can be generated
+	// again without asking
+	Answers map[string]string `yaml:"answers,omitempty"`
+	Files   []Entry           `yaml:"files"`
+}
+
+// Entry is a file generated from a template
+type Entry struct {
+	Path     string `yaml:"path"`            // relative to the project root
+	Template string `yaml:"template"`        // rule whose template generated it
+	Type     string `yaml:"type"`            // project type it was generated for
+	Scope    string `yaml:"scope,omitempty"` // nested config directory it was generated in
+	Date     string `yaml:"date"`            // {{date}} and {{year}} were filled in with
+	Hash     string `yaml:"hash"`            // of the generated content
+}
+
+// Load reads the manifest of the project at root; a project without one
+// has an empty manifest
+func Load(root string) (*Manifest, error) {
+	m := &Manifest{Answers: map[string]string{}}
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(File)))
+	if errors.Is(err, os.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("%s: %w", File, err)
+	}
+	if m.Answers == nil {
+		m.Answers = map[string]string{}
+	}
+	return m, nil
+}
+
+// Marshal returns the manifest as written to File, files sorted by path
+func (m *Manifest) Marshal() ([]byte, error) {
+	sort.Slice(m.Files, func(a, b int) bool { return m.Files[a].Path < m.Files[b].Path })
+	return yaml.Marshal(m)
+}
+
+// Find returns the entry for path, nil when psx didn't generate it
+func (m *Manifest) Find(path string) *Entry {
+	for i := range m.Files {
+		if m.Files[i].Path == path {
+			return &m.Files[i]
+		}
+	}
+	return nil
+}
+
+// Set records that path was generated with entry, replacing what was
+// recorded for it before
+func (m *Manifest) Set(entry Entry) {
+	if existing := m.Find(entry.Path); existing != nil {
+		*existing = entry
+		return
+	}
+	m.Files = append(m.Files, entry)
+}
+
+// References reports whether an entry was generated with the content
+// with hash
+func (m *Manifest) References(hash string) bool {
+	for _, entry := range m.Files {
+		if entry.Hash == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// BasePath is where the generated content with hash is kept
+func BasePath(root, hash string) string {
+	return filepath.Join(root, filepath.FromSlash(BaseDir), hash)
+}
+
+// Base returns the content entry was generated with
+func Base(root string, entry Entry) (string, error) {
+	data, err := os.ReadFile(BasePath(root, entry.Hash))
+	if err != nil {
+		return "", fmt.Errorf("generated content of %s is missing from %s", entry.Path, BaseDir)
+	}
+	return string(data), nil
+}
+
+// Hash identifies generated content
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,232 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// jsonObject is where an object is in the source
+type jsonObject struct {
+	open, close int // offsets of { and }
+	members     int
+	firstKey    int // offset of the first key
+	lastEnd     int // offset just after the last member's value
+}
+
+// scanJSON finds every object that can be reached through keys alone,
+// by dotted path ("" for the root)
+func scanJSON(data []byte) (map[string]*jsonObject, error) {
+	type frame struct {
+		path      string
+		obj       *jsonObject // nil for arrays
+		key       string
+		expectKey bool
+	}
+
+	objects := map[string]*jsonObject{}
+	stack := []*frame{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	childPath := func() string {
+		if len(stack) == 0 {
+			return ""
+		}
+		top := stack[len(stack)-1]
+		switch {
+		case top.obj == nil:
+			return top.path + "[]" // never matched
+		case top.path == "":
+			return top.key
+		}
+		return top.path + "." + top.key
+	}
+	valueDone := func(end int) {
+		if len(stack) == 0 {
+			return
+		}
+		if top := stack[len(stack)-1]; top.obj != nil {
+			top.obj.members++
+			top.obj.lastEnd = end
+			top.expectKey = true
+		}
+	}
+
+	for {
+		before := int(dec.InputOffset())
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		end := int(dec.InputOffset())
+
+		switch t := tok.(type) {
+		case json.Delim:
+			switch t {
+			case '{':
+				obj := &jsonObject{open: end - 1}
+				path := childPath()
+				objects[path] = obj
+				stack = append(stack, &frame{path: path, obj: obj, expectKey: true})
+			case '[':
+				stack = append(stack, &frame{path: childPath()})
+			case '}', ']':
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if top.obj != nil {
+					top.obj.close = end - 1
+				}
+				valueDone(end)
+			}
+		default:
+			top := stack[len(stack)-1]
+			if top.obj != nil && top.expectKey {
+				top.key, _ = t.(string)
+				top.expectKey = false
+				if top.obj.members == 0 {
+					top.obj.firstKey = before + len(data[before:]) - len(bytes.TrimLeft(data[before:], " \t\r\n,"))
+				}
+				continue
+			}
+			valueDone(end)
+		}
+	}
+	return objects, nil
+}
+
+// addJSON inserts segments = value as the last member of the deepest
+// object on the path that exists
+func addJSON(data []byte, segments []string, value any) ([]byte, error) {
+	objects, err := scanJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := objects[""]
+	if !ok {
+		return nil, fmt.Errorf("top level is not an object")
+	}
+
+	depth, obj := 0, root
+	for depth < len(segments)-1 {
+		child, ok := objects[strings.Join(segments[:depth+1], ".")]
+		if !ok {
+			break
+		}
+		depth, obj = depth+1, child
+	}
+
+	unit := jsonIndentUnit(data, root)
+	parentIndent := lineIndent(data, obj.open)
+	inline := obj.members > 0 && !bytes.Contains(data[obj.open:obj.firstKey], []byte("\n"))
+
+	memberIndent := parentIndent + unit
+	if obj.members > 0 && !inline {
+		memberIndent = lineIndent(data, obj.firstKey)
+	}
+
+	key, err := encodeJSON(segments[depth], "", "")
+	if err != nil {
+		return nil, err
+	}
+	rest := nested(segments[depth+1:], value)
+	var member string
+	if inline {
+		encoded, err := inlineJSON(rest)
+		if err != nil {
+			return nil, err
+		}
+		member = key + ": " + encoded
+	} else {
+		encoded, err := encodeJSON(rest, memberIndent, unit)
+		if err != nil {
+			return nil, err
+		}
+		member = memberIndent + key + ": " + encoded
+	}
+
+	var out bytes.Buffer
+	switch {
+	case obj.members == 0:
+		out.Write(data[:obj.open+1])
+		out.WriteString("\n" + member + "\n" + parentIndent)
+		out.Write(data[obj.close:])
+	case inline:
+		out.Write(data[:obj.lastEnd])
+		out.WriteString(", " + member)
+		out.Write(data[obj.lastEnd:])
+	default:
+		out.Write(data[:obj.lastEnd])
+		out.WriteString(",\n" + member)
+		out.Write(data[obj.lastEnd:])
+	}
+	return out.Bytes(), nil
+}
+
+// encodeJSON encodes without escaping <, > and &, which show up in
+// version ranges like ">=18"
+func encodeJSON(value any, prefix, indent string) (string, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if indent != "" {
+		enc.SetIndent(prefix, indent)
+	}
+	if err := enc.Encode(value); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// inlineJSON encodes like a one-line object in the file is written, with a
+// space after each colon and comma
+func inlineJSON(value any) (string, error) {
+	m, ok := value.(map[string]any)
+	if !ok {
+		return encodeJSON(value, "", "")
+	}
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	members := make([]string, 0, len(keys))
+	for _, key := range keys {
+		k, err := encodeJSON(key, "", "")
+		if err != nil {
+			return "", err
+		}
+		v, err := inlineJSON(m[key])
+		if err != nil {
+			return "", err
+		}
+		members = append(members, k+": "+v)
+	}
+	return "{" + strings.Join(members, ", ") + "}", nil
+}
+
+// jsonIndentUnit is the indentation of the root's members, two spaces when
+// that can't be told
+func jsonIndentUnit(data []byte, root *jsonObject) string {
+	if root.members > 0 && bytes.Contains(data[root.open:root.firstKey], []byte("\n")) {
+		if indent := lineIndent(data, root.firstKey); indent != "" {
+			return indent
+		}
+	}
+	return "  "
+}
+
+// lineIndent returns the leading whitespace of the line offset is on
+func lineIndent(data []byte, offset int) string {
+	start := bytes.LastIndexByte(data[:offset], '\n') + 1
+	line := data[start:]
+	return string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+}
@@ -0,0 +1,124 @@
+// Package manifest adds fields to JSON, YAML and TOML manifests such as
+// package.json in place. Existing keys keep their order, formatting and
+// comments; new keys go after the existing ones of their parent
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+// Field is a value to add at a dotted key, e.g. "scripts.test"
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Format returns the format of a manifest from its name, "" when unknown
+func Format(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".yml", ".yaml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	}
+	return ""
+}
+
+// Missing returns the fields data doesn't have. A field counts as present
+// when a parent key holds something other than a table, e.g. "repository"
+// given as a string covers "repository.url"
+func Missing(path string, data []byte, fields []Field) ([]Field, error) {
+	doc, err := decode(path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := []Field{}
+	for _, field := range fields {
+		if !present(doc, strings.Split(field.Key, ".")) {
+			missing = append(missing, field)
+		}
+	}
+	return missing, nil
+}
+
+// Add returns data with the missing fields added. Fields that are already
+// present are left alone
+func Add(path string, data []byte, fields []Field) ([]byte, error) {
+	missing, err := Missing(path, data, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range missing {
+		segments := strings.Split(field.Key, ".")
+		switch Format(path) {
+		case FormatJSON:
+			data, err = addJSON(data, segments, field.Value)
+		case FormatYAML:
+			data, err = addYAML(data, segments, field.Value)
+		case FormatTOML:
+			data, err = addTOML(data, segments, field.Value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to add %s: %w", path, field.Key, err)
+		}
+	}
+	return data, nil
+}
+
+func decode(path string, data []byte) (map[string]any, error) {
+	doc := map[string]any{}
+	var err error
+	switch Format(path) {
+	case FormatJSON:
+		err = json.Unmarshal(data, &doc)
+	case FormatYAML:
+		err = yaml.Unmarshal(data, &doc)
+	case FormatTOML:
+		err = toml.Unmarshal(data, &doc)
+	default:
+		return nil, fmt.Errorf("%s: unsupported manifest format", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return doc, nil
+}
+
+func present(doc map[string]any, segments []string) bool {
+	value, ok := doc[segments[0]]
+	if !ok {
+		return false
+	}
+	if len(segments) == 1 {
+		return true
+	}
+	child, ok := value.(map[string]any)
+	if !ok {
+		return true
+	}
+	return present(child, segments[1:])
+}
+
+// nested turns a.b = v into {a: {b: v}}, or v when there are no segments
+func nested(segments []string, value any) any {
+	for i := len(segments) - 1; i >= 0; i-- {
+		value = map[string]any{segments[i]: value}
+	}
+	return value
+}
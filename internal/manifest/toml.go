@@ -0,0 +1,103 @@
+package manifest
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+var (
+	tomlTable   = regexp.MustCompile(`^\s*\[([^\[\]]+)\]\s*(#.*)?$`)
+	tomlBareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// addTOML adds segments = value as a dotted key at the end of the deepest
+// table on the path that exists. The file is edited as text, so nothing
+// else in it changes
+func addTOML(data []byte, segments []string, value any) ([]byte, error) {
+	rendered, err := tomlValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if string(data) == "" {
+		lines = nil
+	}
+
+	// where each table starts; the root table starts at the top
+	tables := map[string]int{"": -1}
+	headers := []int{}
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "[") {
+			headers = append(headers, i)
+			if m := tomlTable.FindStringSubmatch(line); m != nil {
+				tables[normalizeTOMLKey(m[1])] = i
+			}
+		}
+	}
+
+	depth := 0
+	for n := len(segments) - 1; n > 0; n-- {
+		if _, ok := tables[strings.Join(segments[:n], ".")]; ok {
+			depth = n
+			break
+		}
+	}
+	start := tables[strings.Join(segments[:depth], ".")]
+
+	// the table ends at the next header; new keys go after its last line
+	// that isn't blank
+	end := len(lines)
+	for _, header := range headers {
+		if header > start {
+			end = header
+			break
+		}
+	}
+	at := end
+	for at > start+1 && strings.TrimSpace(lines[at-1]) == "" {
+		at--
+	}
+
+	keys := make([]string, 0, len(segments)-depth)
+	for _, segment := range segments[depth:] {
+		if !tomlBareKey.MatchString(segment) {
+			segment = `"` + strings.ReplaceAll(segment, `"`, `\"`) + `"`
+		}
+		keys = append(keys, segment)
+	}
+	added := []string{strings.Join(keys, ".") + " = " + rendered}
+	if at < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[at]), "[") {
+		// keep the new key apart from the next header
+		added = append(added, "")
+	}
+
+	out := append(append(append([]string{}, lines[:at]...), added...), lines[at:]...)
+	return []byte(strings.Join(out, "\n") + "\n"), nil
+}
+
+// tomlValue encodes a value, strings as basic "..." strings
+func tomlValue(value any) (string, error) {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s), nil
+	}
+	encoded, err := toml.Marshal(map[string]any{"v": value})
+	if err != nil {
+		return "", err
+	}
+	_, rendered, _ := strings.Cut(strings.TrimSpace(string(encoded)), " = ")
+	return rendered, nil
+}
+
+// normalizeTOMLKey turns a table name like `tool . "poetry"` into
+// tool.poetry
+func normalizeTOMLKey(key string) string {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
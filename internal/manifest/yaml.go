@@ -0,0 +1,53 @@
+package manifest
+
+import (
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/parser"
+
+	"github.com/m-mdy-m/psx/internal/utils"
+)
+
+// addYAML merges segments = value into the deepest mapping on the path
+// that exists, keeping comments
+func addYAML(data []byte, segments []string, value any) ([]byte, error) {
+	file, err := parser.ParseBytes(data, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Docs) == 0 || file.Docs[0].Body == nil {
+		out := append([]byte{}, data...)
+		if len(out) > 0 && out[len(out)-1] != '\n' {
+			out = append(out, '\n')
+		}
+		encoded, err := yaml.Marshal(nested(segments, value))
+		if err != nil {
+			return nil, err
+		}
+		return append(out, encoded...), nil
+	}
+
+	depth := len(segments) - 1
+	for depth > 0 {
+		if _, err := yamlPath(segments[:depth]).FilterFile(file); err == nil {
+			break
+		}
+		depth--
+	}
+
+	node, err := yaml.ValueToNode(nested(segments[depth:], value))
+	if err != nil {
+		return nil, err
+	}
+	if err := yamlPath(segments[:depth]).MergeFromNode(file, node); err != nil {
+		return nil, err
+	}
+	return []byte(utils.KeepLayout(string(data), file.String())), nil
+}
+
+func yamlPath(segments []string) *yaml.Path {
+	builder := (&yaml.PathBuilder{}).Root()
+	for _, segment := range segments {
+		builder = builder.Child(segment)
+	}
+	return builder.Build()
+}
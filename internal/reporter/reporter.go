@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/fatih/color"
 
@@ -47,11 +48,16 @@ func (r *Reporter) reportTable() error {
 	errors := []rules.RuleResult{}
 	warnings := []rules.RuleResult{}
 	infos := []rules.RuleResult{}
+	suppressed := []rules.RuleResult{}
 
 	for _, result := range r.result.Results {
 		if result.Passed {
 			continue
 		}
+		if result.Suppressed {
+			suppressed = append(suppressed, result)
+			continue
+		}
 
 		switch result.Severity {
 		case config.SeverityError:
@@ -63,10 +69,11 @@ func (r *Reporter) reportTable() error {
 		}
 	}
 
-	// Sort by rule ID
-	sort.Slice(errors, func(i, j int) bool { return errors[i].RuleID < errors[j].RuleID })
-	sort.Slice(warnings, func(i, j int) bool { return warnings[i].RuleID < warnings[j].RuleID })
-	sort.Slice(infos, func(i, j int) bool { return infos[i].RuleID < infos[j].RuleID })
+	// Sort by scope, then rule ID
+	sortResults(errors)
+	sortResults(warnings)
+	sortResults(infos)
+	sortResults(suppressed)
 
 	// Header
 	if !f.GlobalFlags.Quiet {
@@ -91,6 +98,10 @@ func (r *Reporter) reportTable() error {
 		r.printSection("INFO", infos, config.SeverityInfo)
 	}
 
+	if len(suppressed) > 0 && !f.GlobalFlags.Quiet {
+		r.printSuppressed(suppressed)
+	}
+
 	// Summary
 	if !f.GlobalFlags.Quiet {
 		fmt.Println()
@@ -105,12 +116,18 @@ func (r *Reporter) reportJSON() error {
 	output := map[string]any{
 		"status": string(r.result.Status),
 		"summary": map[string]int{
-			"total":    r.result.Summary.Total,
-			"passed":   r.result.Summary.Passed,
-			"errors":   r.result.Summary.Errors,
-			"warnings": r.result.Summary.Warnings,
-			"info":     r.result.Summary.Info,
+			"total":      r.result.Summary.Total,
+			"passed":     r.result.Summary.Passed,
+			"errors":     r.result.Summary.Errors,
+			"warnings":   r.result.Summary.Warnings,
+			"info":       r.result.Summary.Info,
+			"suppressed": r.result.Summary.Suppressed,
 		},
+		"suppressions": map[string][]string{
+			"expired": suppressionNames(r.result.ExpiredSuppressions),
+			"unused":  suppressionNames(r.result.UnusedSuppressions),
+		},
+		"drift": driftForJSON(r.result.Drift),
 		"context": map[string]string{
 			"project_path": r.result.Context.ProjectPath,
 			"project_type": r.result.Context.ProjectType,
@@ -132,12 +149,18 @@ func (r *Reporter) formatResultsForJSON() []map[string]any {
 
 	for _, result := range r.result.Results {
 		results = append(results, map[string]any{
-			"rule_id":  result.RuleID,
-			"passed":   result.Passed,
-			"severity": string(result.Severity),
-			"message":  result.Message,
-			"fix_hint": result.FixHint,
-			"doc_url":  result.DocURL,
+			"rule_id":            result.RuleID,
+			"passed":             result.Passed,
+			"severity":           string(result.Severity),
+			"message":            result.Message,
+			"fix_hint":           result.FixHint,
+			"doc_url":            result.DocURL,
+			"path":               result.Path,
+			"scope":              result.Scope,
+			"source":             result.Source,
+			"suppressed":         result.Suppressed,
+			"suppression_reason": result.SuppressionReason,
+			"escalation":         escalationForJSON(result.Escalation),
 		})
 	}
 
@@ -161,10 +184,20 @@ func (r *Reporter) printSection(title string, results []rules.RuleResult, severi
 
 	// Results
 	for _, result := range results {
-		fmt.Printf("  %s %s\n", icon, result.RuleID)
+		if result.Scope != "" {
+			fmt.Printf("  %s %s (%s/)\n", icon, result.RuleID, result.Scope)
+		} else {
+			fmt.Printf("  %s %s\n", icon, result.RuleID)
+		}
 
 		if !f.GlobalFlags.Quiet {
 			fmt.Printf("      %s\n", result.Message)
+			if result.Path != "" {
+				fmt.Printf("      Path: %s\n", result.Path)
+			}
+			if e := result.Escalation; e != nil {
+				fmt.Printf("      Becomes %s on %s\n", e.Severity, e.Date.Format(time.DateOnly))
+			}
 		}
 
 		if f.GlobalFlags.Verbose {
@@ -174,6 +207,40 @@ func (r *Reporter) printSection(title string, results []rules.RuleResult, severi
 			if result.DocURL != "" {
 				fmt.Printf("      Docs: %s\n", result.DocURL)
 			}
+			if result.Source != "" {
+				fmt.Printf("      Severity from: %s\n", result.Source)
+			}
+		}
+	}
+
+	fmt.Println()
+}
+
+// printSuppressed lists failures that were accepted in suppressions:, with
+// the reason given for each
+func (r *Reporter) printSuppressed(results []rules.RuleResult) {
+	f := flags.GetFlags()
+
+	if f.GlobalFlags.NoColor {
+		fmt.Printf("- SUPPRESSED (%d)\n", len(results))
+	} else {
+		color.New(color.Faint).Printf("- SUPPRESSED (%d)\n", len(results))
+	}
+
+	fmt.Println()
+
+	for _, result := range results {
+		if result.Scope != "" {
+			fmt.Printf("  - %s (%s/)\n", result.RuleID, result.Scope)
+		} else {
+			fmt.Printf("  - %s\n", result.RuleID)
+		}
+		fmt.Printf("      Reason: %s\n", result.SuppressionReason)
+		if f.GlobalFlags.Verbose {
+			fmt.Printf("      %s\n", result.Message)
+			if result.Path != "" {
+				fmt.Printf("      Path: %s\n", result.Path)
+			}
 		}
 	}
 
@@ -188,12 +255,21 @@ func (r *Reporter) printSummary() {
 	errors := r.result.Summary.Errors
 	warnings := r.result.Summary.Warnings
 
+	suppressed := r.result.Summary.Suppressed
+
 	// Compact summary
 	if !f.GlobalFlags.Verbose {
 		if errors > 0 || warnings > 0 {
-			fmt.Printf("Result: %d errors, %d warnings\n", errors, warnings)
+			fmt.Printf("Result: %d errors, %d warnings", errors, warnings)
+			if suppressed > 0 {
+				fmt.Printf(", %d suppressed", suppressed)
+			}
+			fmt.Println()
 		} else {
 			msg := resources.FormatMessage("check", "success_all", passed)
+			if suppressed > 0 {
+				msg = fmt.Sprintf("%s (%d suppressed)", msg, suppressed)
+			}
 			fmt.Println(msg)
 		}
 	} else {
@@ -206,6 +282,9 @@ func (r *Reporter) printSummary() {
 		if warnings > 0 {
 			fmt.Printf("Warnings: %d\n", warnings)
 		}
+		if suppressed > 0 {
+			fmt.Printf("Suppressed: %d\n", suppressed)
+		}
 	}
 
 	// Status
@@ -238,6 +317,45 @@ func (r *Reporter) printStatus() {
 	}
 }
 
+func sortResults(results []rules.RuleResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Scope != results[j].Scope {
+			return results[i].Scope < results[j].Scope
+		}
+		return results[i].RuleID < results[j].RuleID
+	})
+}
+
+func escalationForJSON(e *config.Escalation) map[string]string {
+	if e == nil {
+		return nil
+	}
+	return map[string]string{
+		"severity": string(e.Severity),
+		"date":     e.Date.Format(time.DateOnly),
+	}
+}
+
+func driftForJSON(drift []rules.Drift) []map[string]any {
+	result := []map[string]any{}
+	for _, d := range drift {
+		result = append(result, map[string]any{
+			"path":     d.Path,
+			"template": d.Template,
+			"edited":   d.Edited,
+		})
+	}
+	return result
+}
+
+func suppressionNames(suppressions []config.Suppression) []string {
+	names := make([]string, 0, len(suppressions))
+	for _, s := range suppressions {
+		names = append(names, s.String())
+	}
+	return names
+}
+
 func getSeverityIcon(severity config.Severity) string {
 	icons := map[config.Severity]string{
 		config.SeverityError:   "✗",
@@ -28,10 +28,12 @@ func GetContributing() string {
 
 func GetGitignore(projectType string) string {
 	common := gitignores.Common
-	specific := getTemplate(map[string]string{
+	// only types with their own entries add any; other types get the
+	// common ones rather than another language's
+	specific := map[string]string{
 		"nodejs": gitignores.NodeJS,
 		"go":     gitignores.Go,
-	}, projectType)
+	}[projectType]
 
 	if specific != "" {
 		return common + "\n\n" + specific
@@ -101,6 +103,60 @@ func GetDockerignore(projectType string) string {
 	}
 }
 
+// GetManifest returns the manifest file of a project type and the fields it
+// should have, "" when the type has none. Unlike the other templates no
+// placeholder info is filled in: a field whose value needs something info
+// doesn't have gets a nil value
+func GetManifest(info *ProjectInfo, projectType string) (string, []ManifestField) {
+	language, ok := languages.Languages[projectType]
+	if !ok || language.Manifest == nil {
+		return "", nil
+	}
+	vars := info.ToVars()
+
+	fields := []ManifestField{}
+	for _, field := range language.Manifest.Fields {
+		value, ok := fillManifestValue(field.Value, vars)
+		if !ok {
+			value = nil
+		}
+		fields = append(fields, ManifestField{Key: field.Key, Value: value})
+	}
+	return language.Manifest.File, fields
+}
+
+// fillManifestValue replaces the placeholders in the strings of value. ok
+// is false when one of them has no value
+func fillManifestValue(value any, vars map[string]string) (any, bool) {
+	switch v := value.(type) {
+	case string:
+		if hasEmptyVar(v, vars) {
+			return nil, false
+		}
+		return replaceVars(v, vars), true
+	case map[string]any:
+		filled := make(map[string]any, len(v))
+		for key, item := range v {
+			item, ok := fillManifestValue(item, vars)
+			if !ok {
+				return nil, false
+			}
+			filled[key] = item
+		}
+		return filled, true
+	}
+	return value, true
+}
+
+func hasEmptyVar(template string, vars map[string]string) bool {
+	for _, match := range placeholderPattern.FindAllStringSubmatch(template, -1) {
+		if vars[match[1]] == "" {
+			return true
+		}
+	}
+	return false
+}
+
 func GetDockerComposeWithPrompt(info *ProjectInfo, projectType string) string {
 	if info == nil {
 		info = getDefaultProjectInfo()
@@ -108,7 +164,7 @@ func GetDockerComposeWithPrompt(info *ProjectInfo, projectType string) string {
 	vars := info.ToVars()
 
 	// Ask user if they want database
-	useDB := utils.Prompt("Do you want to include database in docker-compose?")
+	useDB := utils.AskConfirm("docker_compose_db", "Do you want to include database in docker-compose?")
 
 	var template string
 	if useDB {
@@ -241,27 +297,34 @@ func GetCIConfig(info *ProjectInfo, projectType string) string {
 		info = getDefaultProjectInfo()
 	}
 
-	// Prompt user for platform choice
-	platform, err := utils.PromptChoice(
-		"Which CI/CD platform?",
-		[]string{"GitHub Actions", "GitLab CI", "Skip"},
-	)
-
-	if err != nil || platform == "Skip" {
-		logger.Info("Skipping CI/CD configuration")
-		return ""
-	}
+	// Prompt user for platform choice; this makes one file, so "both"
+	// from an answer gives the GitHub workflow
+	platform := utils.AskChoice("ci_platform", "Which CI/CD platform?", CIChoices(false))
 
 	switch platform {
-	case "GitHub Actions":
+	case "github", "both":
 		return GetGitHubActionsWorkflow(info, projectType)
-	case "GitLab CI":
+	case "gitlab":
 		return GetGitLabCIConfig(info, projectType)
 	default:
+		logger.Info("Skipping CI/CD configuration")
 		return ""
 	}
 }
 
+// CIChoices are the answers to ci_platform, with both platforms at once
+// when withBoth
+func CIChoices(withBoth bool) []utils.Choice {
+	choices := []utils.Choice{
+		{Value: "github", Label: "GitHub Actions"},
+		{Value: "gitlab", Label: "GitLab CI"},
+	}
+	if withBoth {
+		choices = append(choices, utils.Choice{Value: "both", Label: "Both"})
+	}
+	return append(choices, utils.Choice{Value: "skip", Label: "Skip"})
+}
+
 func GetGitHubActionsWorkflow(info *ProjectInfo, projectType string) string {
 	if info == nil {
 		info = getDefaultProjectInfo()
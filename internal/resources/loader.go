@@ -5,6 +5,8 @@ package resources
 import (
 	"embed"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -78,6 +80,8 @@ func init() {
 	logger.Verbose("All resources loaded successfully")
 }
 
+var placeholderPattern = regexp.MustCompile(`\{\{(\w+)\}\}`)
+
 func replaceVars(template string, vars map[string]string) string {
 	result := template
 	for key, value := range vars {
@@ -86,8 +90,22 @@ func replaceVars(template string, vars map[string]string) string {
 	}
 	return result
 }
+
+// templateDate pins {{year}} and {{date}}, so a file can be generated again
+// as it was on the day psx created it. The zero time means today
+var templateDate time.Time
+
+// SetTemplateDate pins the date templates are filled in with; the zero
+// time goes back to today
+func SetTemplateDate(t time.Time) {
+	templateDate = t
+}
+
 func getCurrentVars() map[string]string {
-	now := time.Now()
+	now := templateDate
+	if now.IsZero() {
+		now = time.Now()
+	}
 	return map[string]string{
 		"year": fmt.Sprintf("%d", now.Year()),
 		"date": now.Format("2006-01-02"),
@@ -105,6 +123,20 @@ func NormalizeProjectType(projectType string) string {
 	return projectType
 }
 
+// ProjectTypes lists the canonical project types psx knows
+func ProjectTypes() []string {
+	seen := map[string]bool{}
+	types := []string{}
+	for _, canonical := range languages.Aliases {
+		if !seen[canonical] {
+			seen[canonical] = true
+			types = append(types, canonical)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
 func FormatMessage(category, key string, args ...any) string {
 	msg := GetMessage(category, key)
 	if msg == "" {
@@ -122,9 +154,15 @@ func getTemplate(templates map[string]string, projectType string) string {
 		return t
 	}
 
-	for _, t := range templates {
-		if t != "" {
-			return t
+	// the same one every time, so a file generated again doesn't change
+	keys := make([]string, 0, len(templates))
+	for key := range templates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if templates[key] != "" {
+			return templates[key]
 		}
 	}
 
@@ -144,6 +182,12 @@ func getScriptTemplate(scripts ScriptPlatformConfig, projectType string) string
 	return ""
 }
 
+// DefaultProjectInfo is the placeholder project info templates are filled
+// in with when a project has none
+func DefaultProjectInfo() *ProjectInfo {
+	return getDefaultProjectInfo()
+}
+
 func getDefaultProjectInfo() *ProjectInfo {
 	info := &ProjectInfo{
 		Name:        "project",
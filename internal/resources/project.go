@@ -14,7 +14,7 @@ import (
 
 const projectCacheFile = ".psx-project.yml"
 
-func GetProjectInfo(projectPath string, interactive bool) *ProjectInfo {
+func GetProjectInfo(projectPath string, interactive, save bool) *ProjectInfo {
 	if info, err := loadProjectInfo(projectPath); err == nil && info != nil {
 		logger.Verbose("Using cached project info")
 		return info
@@ -34,6 +34,11 @@ func GetProjectInfo(projectPath string, interactive bool) *ProjectInfo {
 	}
 
 	info.buildDerived()
+	// info made of defaults for unanswered questions isn't kept, so the
+	// next run asks again, and a run that mustn't write keeps nothing
+	if len(utils.Unanswered()) > 0 || !save {
+		return info
+	}
 	if err := saveProjectInfo(projectPath, info); err != nil {
 		logger.Warning(fmt.Sprintf("Failed to save project info: %v", err))
 	}
@@ -88,13 +93,13 @@ func (p *ProjectInfo) promptUser() {
 	fmt.Println("Project Information:")
 	fmt.Println()
 
-	p.Name = utils.PromptInput("Project name", p.Name)
-	p.Description = utils.PromptInput("Description", p.Description)
-	p.Author = utils.PromptInput("Author", p.Author)
-	p.Email = utils.PromptInput("Email", p.Email)
-	p.GitHubUser = utils.PromptInput("GitHub username", p.GitHubUser)
-	p.RepoName = utils.PromptInput("Repository name", p.RepoName)
-	p.License = utils.PromptInput("License", p.License)
+	p.Name = utils.AskInput("project_name", "Project name", p.Name)
+	p.Description = utils.AskInput("description", "Description", p.Description)
+	p.Author = utils.AskInput("author", "Author", p.Author)
+	p.Email = utils.AskInput("email", "Email", p.Email)
+	p.GitHubUser = utils.AskInput("github_user", "GitHub username", p.GitHubUser)
+	p.RepoName = utils.AskInput("repo_name", "Repository name", p.RepoName)
+	p.License = utils.AskInput("license", "License", p.License)
 }
 
 func (p *ProjectInfo) setDefaults() {
@@ -111,5 +111,22 @@ type ScriptsConfig struct {
 type ScriptPlatformConfig map[string]string
 
 type LanguagesConfig struct {
-	Aliases map[string]string `yaml:"aliases"`
+	Aliases   map[string]string         `yaml:"aliases"`
+	Languages map[string]LanguageConfig `yaml:"languages"`
+}
+
+type LanguageConfig struct {
+	Name     string          `yaml:"name"`
+	Manifest *ManifestConfig `yaml:"manifest,omitempty"`
+}
+
+// ManifestConfig lists the fields a language's manifest should have
+type ManifestConfig struct {
+	File   string          `yaml:"file"`
+	Fields []ManifestField `yaml:"fields"`
+}
+
+type ManifestField struct {
+	Key   string `yaml:"key"`
+	Value any    `yaml:"value"`
 }
@@ -0,0 +1,136 @@
+package rules
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/m-mdy-m/psx/internal/config"
+	"github.com/m-mdy-m/psx/internal/logger"
+)
+
+const (
+	defaultCommandTimeout = 60 * time.Second
+	commandOutputLines    = 20
+	processWaitDelay      = 500 * time.Millisecond
+)
+
+// CommandRunner runs command rules. Rules are checked concurrently, so the
+// runner hands out a fixed number of slots to keep local tools from
+// swamping the machine
+type CommandRunner struct {
+	ctx   *Context
+	slots chan struct{}
+}
+
+func NewCommandRunner(ctx *Context, concurrency int) *CommandRunner {
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+	return &CommandRunner{
+		ctx:   ctx,
+		slots: make(chan struct{}, concurrency),
+	}
+}
+
+func (c *CommandRunner) Run(rule *config.ActiveRule) RuleResult {
+	custom := rule.Custom
+	command := config.GetCommand(custom.Command, c.ctx.ProjectType)
+	if command == "" {
+		logger.Verbose(fmt.Sprintf("No command for %s in %s projects", rule.ID, c.ctx.ProjectType))
+		return RuleResult{
+			RuleID:   rule.ID,
+			Passed:   true,
+			Severity: rule.Severity,
+			Message:  "Not applicable for this project type",
+		}
+	}
+
+	c.slots <- struct{}{}
+	defer func() { <-c.slots }()
+
+	output, err := c.exec(custom, command)
+	if err == nil {
+		return RuleResult{
+			RuleID:   rule.ID,
+			Passed:   true,
+			Severity: rule.Severity,
+			Message:  "OK",
+		}
+	}
+
+	logger.Verbose(fmt.Sprintf("Command rule %s: %v", rule.ID, err))
+
+	message := lastLines(output, commandOutputLines)
+	if message == "" {
+		message = fmt.Sprintf("%s (%v)", rule.Metadata.Message, err)
+	}
+	return RuleResult{
+		RuleID:   rule.ID,
+		Passed:   false,
+		Severity: rule.Severity,
+		Message:  message,
+		FixHint:  rule.Metadata.FixHint,
+		DocURL:   rule.Metadata.DocURL,
+	}
+}
+
+func (c *CommandRunner) exec(custom *config.CustomRule, command string) (string, error) {
+	timeout := defaultCommandTimeout
+	if custom.Timeout != "" {
+		if d, err := time.ParseDuration(custom.Timeout); err == nil && d > 0 {
+			timeout = d
+		}
+	}
+
+	workDir := c.ctx.ProjectPath
+	if custom.WorkDir != "" {
+		workDir = filepath.Join(c.ctx.ProjectPath, filepath.FromSlash(custom.WorkDir))
+	}
+
+	runCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(runCtx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(runCtx, "sh", "-c", command)
+	}
+	cmd.Dir = workDir
+	// don't wait on children that outlive a killed process and keep the pipes open
+	cmd.WaitDelay = processWaitDelay
+	cmd.Env = append(os.Environ(),
+		"PSX_PROJECT_PATH="+c.ctx.ProjectPath,
+		"PSX_PROJECT_TYPE="+c.ctx.ProjectType,
+	)
+	for key, value := range custom.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	logger.Verbose(fmt.Sprintf("Running command for %s: %s", custom.ID, command))
+	err := cmd.Run()
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		return output.String(), fmt.Errorf("timed out after %s", timeout)
+	}
+	return output.String(), err
+}
+
+func lastLines(output string, n int) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) > n {
+		lines = append([]string{fmt.Sprintf("... (%d lines omitted)", len(lines)-n)}, lines[len(lines)-n:]...)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -58,7 +58,7 @@ func (cg *ContentGenerator) generateByRuleID(ruleID string) string {
 		return resources.GetCodeOfConduct(cg.projectInfo)
 	case "pull_request_template":
 		return resources.GetPullRequestTemplate(cg.projectInfo)
-	case "codeowners":
+	case "codeowners", "code_owners":
 		return resources.GetCodeowners(cg.projectInfo)
 
 	// Quality tools
@@ -166,22 +166,19 @@ func (cg *ContentGenerator) getAPIDocPath() string {
 func (cg *ContentGenerator) generateCIConfig() (map[string]string, error) {
 	result := make(map[string]string)
 
-	platform, err := utils.PromptChoice(
-		"Which CI/CD platform do you want to use?",
-		[]string{"GitHub Actions", "GitLab CI", "Both", "Skip"},
-	)
-	if err != nil || platform == "Skip" {
+	platform := utils.AskChoice("ci_platform", "Which CI/CD platform do you want to use?", resources.CIChoices(true))
+	if platform == "skip" {
 		return nil, nil
 	}
 
 	switch platform {
-	case "GitHub Actions":
+	case "github":
 		workflow := resources.GetGitHubActionsWorkflow(cg.projectInfo, cg.projectType)
 		result[".github/workflows/ci.yml"] = workflow
-	case "GitLab CI":
+	case "gitlab":
 		config := resources.GetGitLabCIConfig(cg.projectInfo, cg.projectType)
 		result[".gitlab-ci.yml"] = config
-	case "Both":
+	case "both":
 		workflow := resources.GetGitHubActionsWorkflow(cg.projectInfo, cg.projectType)
 		result[".github/workflows/ci.yml"] = workflow
 		config := resources.GetGitLabCIConfig(cg.projectInfo, cg.projectType)
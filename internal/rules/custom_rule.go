@@ -63,6 +63,7 @@ func (h *CustomHandler) applyFiles(fixCtx *FixContext) []*FixResult {
 		result := &FixResult{
 			RuleID: fmt.Sprintf("custom:file:%s", customFile.Path),
 		}
+		mark := fixCtx.Plan.begin(result.RuleID)
 
 		if fixCtx.DryRun {
 			// Dry run preview
@@ -72,13 +73,14 @@ func (h *CustomHandler) applyFiles(fixCtx *FixContext) []*FixResult {
 					Type:        ChangeCreateFile,
 					Path:        fullPath,
 					Description: fmt.Sprintf("Create %s", customFile.Path),
-					Content:     formatContent(customFile.Content, 10),
+					Content:     customFile.Content,
 				},
 			}
 		} else {
 			// Actually create file
-			err := utils.CreateFile(fullPath, customFile.Content)
+			err := fixCtx.Plan.WriteFile(fullPath, []byte(customFile.Content))
 			if err != nil {
+				fixCtx.Plan.discard(mark)
 				result.Error = err
 				logger.Error(fmt.Sprintf("Failed to create %s: %v", customFile.Path, err))
 			} else {
@@ -116,6 +118,7 @@ func (h *CustomHandler) applyFolders(fixCtx *FixContext) []*FixResult {
 		result := &FixResult{
 			RuleID: fmt.Sprintf("custom:folder:%s", customFolder.Path),
 		}
+		mark := fixCtx.Plan.begin(result.RuleID)
 
 		if fixCtx.DryRun {
 			// Dry run preview
@@ -124,8 +127,9 @@ func (h *CustomHandler) applyFolders(fixCtx *FixContext) []*FixResult {
 			result.Changes = changes
 		} else {
 			// Actually create structure
-			changes, err := h.createFolderStructure(customFolder, fullPath)
+			changes, err := h.createFolderStructure(customFolder, fullPath, fixCtx.Plan)
 			if err != nil {
+				fixCtx.Plan.discard(mark)
 				result.Error = err
 				logger.Error(fmt.Sprintf("Failed to create folder structure %s: %v", customFolder.Path, err))
 			} else {
@@ -185,11 +189,11 @@ func (h *CustomHandler) previewStructure(structure map[string]interface{}, baseP
 	return changes
 }
 
-func (h *CustomHandler) createFolderStructure(folder config.CustomFolder, basePath string) ([]Change, error) {
+func (h *CustomHandler) createFolderStructure(folder config.CustomFolder, basePath string, plan *Plan) ([]Change, error) {
 	changes := []Change{}
 
 	// Create root folder
-	err := utils.CreateDir(basePath)
+	err := plan.CreateDir(basePath)
 	if err != nil {
 		return nil, err
 	}
@@ -202,7 +206,7 @@ func (h *CustomHandler) createFolderStructure(folder config.CustomFolder, basePa
 
 	// Create sub-structure
 	if folder.Structure != nil {
-		subChanges, err := h.createStructure(folder.Structure, basePath, folder.Path)
+		subChanges, err := h.createStructure(folder.Structure, basePath, folder.Path, plan)
 		if err != nil {
 			return changes, err
 		}
@@ -212,7 +216,7 @@ func (h *CustomHandler) createFolderStructure(folder config.CustomFolder, basePa
 	return changes, nil
 }
 
-func (h *CustomHandler) createStructure(structure map[string]interface{}, basePath, relativePath string) ([]Change, error) {
+func (h *CustomHandler) createStructure(structure map[string]interface{}, basePath, relativePath string, plan *Plan) ([]Change, error) {
 	changes := []Change{}
 
 	for name, value := range structure {
@@ -221,7 +225,7 @@ func (h *CustomHandler) createStructure(structure map[string]interface{}, basePa
 
 		if subStructure, ok := value.(map[string]interface{}); ok {
 			// It's a folder
-			err := utils.CreateDir(itemPath)
+			err := plan.CreateDir(itemPath)
 			if err != nil {
 				return changes, err
 			}
@@ -234,7 +238,7 @@ func (h *CustomHandler) createStructure(structure map[string]interface{}, basePa
 
 			// Recursively create sub-structure
 			if len(subStructure) > 0 {
-				subChanges, err := h.createStructure(subStructure, itemPath, relItemPath)
+				subChanges, err := h.createStructure(subStructure, itemPath, relItemPath, plan)
 				if err != nil {
 					return changes, err
 				}
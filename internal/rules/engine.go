@@ -3,30 +3,98 @@ package rules
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/m-mdy-m/psx/internal/config"
 	"github.com/m-mdy-m/psx/internal/logger"
 )
 
 type Engine struct {
-	ctx    *Context
-	rules  map[string]*config.ActiveRule
-	checks *Checker
-	fixes  *Fixer
+	ctx      *Context
+	rules    map[string]*config.ActiveRule
+	checks   *Checker
+	fixes    *Fixer
+	scripts  *ScriptRunner
+	plugins  *PluginRunner
+	commands *CommandRunner
 }
 
 func NewEngine(cfg *config.Config, ctx *Context) *Engine {
+	concurrency := 0
+	if cfg.Custom != nil {
+		concurrency = cfg.Custom.Concurrency
+	}
 	return &Engine{
-		ctx:    ctx,
-		rules:  cfg.ActiveRules,
-		checks: NewChecker(ctx),
-		fixes:  NewFixer(ctx),
+		ctx:      ctx,
+		rules:    cfg.ActiveRules,
+		checks:   NewChecker(ctx),
+		fixes:    NewFixer(ctx),
+		scripts:  NewScriptRunner(ctx),
+		plugins:  NewPluginRunner(ctx),
+		commands: NewCommandRunner(ctx, concurrency),
 	}
 }
 
+// Execute checks the project and every nested config scope below it
 func Execute(cfg *config.Config, ctx *Context) (*ExecutionResult, error) {
+	now := config.Now()
+	used := map[config.Suppression]bool{}
+
+	result, err := execute(cfg, ctx, now, used)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range allSuppressions(cfg) {
+		switch {
+		case s.Expired(now):
+			result.ExpiredSuppressions = append(result.ExpiredSuppressions, s)
+		case !used[s]:
+			result.UnusedSuppressions = append(result.UnusedSuppressions, s)
+		}
+	}
+	return result, nil
+}
+
+func execute(cfg *config.Config, ctx *Context, now time.Time, used map[config.Suppression]bool) (*ExecutionResult, error) {
 	engine := NewEngine(cfg, ctx)
-	return engine.Execute()
+	result, err := engine.Execute()
+	if err != nil {
+		return nil, err
+	}
+	applySuppressions(result.Results, cfg.Suppressions, now, used)
+
+	for _, scope := range cfg.Scopes {
+		if len(scope.ActiveRules) == 0 {
+			logger.Verbose(fmt.Sprintf("No active rules for %s/, skipping", scope.Scope))
+			continue
+		}
+		scopeResult, err := execute(scope, ScopeContext(ctx, scope), now, used)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", scope.Scope, err)
+		}
+		result.Results = append(result.Results, scopeResult.Results...)
+	}
+
+	result.Summary = engine.calculateSummary(result.Results)
+	result.Status = engine.determineStatus(result.Summary)
+	return result, nil
+}
+
+// ScopeContext derives the context for a nested config scope. A scope
+// that doesn't set a project type has the type of the project it is in
+func ScopeContext(ctx *Context, scope *config.Config) *Context {
+	projectType := scope.Project.Type
+	if projectType == "" {
+		projectType = ctx.ProjectType
+	}
+	return &Context{
+		ProjectPath: scope.Path,
+		ProjectType: projectType,
+		ProjectInfo: ctx.ProjectInfo,
+		Config:      scope,
+		Scope:       scope.Scope,
+	}
 }
 
 func (e *Engine) Execute() (*ExecutionResult, error) {
@@ -35,10 +103,11 @@ func (e *Engine) Execute() (*ExecutionResult, error) {
 	}
 
 	logger.Verbose(fmt.Sprintf("Executing %d rules...", len(e.rules)))
+	e.logUnusedPlugins()
 
 	// Prepare results slice
 	results := make([]RuleResult, 0, len(e.rules))
-	resultsChan := make(chan RuleResult, len(e.rules))
+	resultsChan := make(chan []RuleResult, len(e.rules))
 
 	var wg sync.WaitGroup
 
@@ -47,8 +116,13 @@ func (e *Engine) Execute() (*ExecutionResult, error) {
 		wg.Add(1)
 		go func(id string, rule *config.ActiveRule) {
 			defer wg.Done()
-			result := e.checkRule(id, rule)
-			resultsChan <- result
+			ruleResults := e.checkRule(id, rule)
+			for i := range ruleResults {
+				ruleResults[i].Scope = e.ctx.Scope
+				ruleResults[i].Source = rule.Source
+				ruleResults[i].Escalation = rule.Escalation
+			}
+			resultsChan <- ruleResults
 		}(ruleID, activeRule)
 	}
 
@@ -59,8 +133,8 @@ func (e *Engine) Execute() (*ExecutionResult, error) {
 	}()
 
 	// Collect results
-	for result := range resultsChan {
-		results = append(results, result)
+	for ruleResults := range resultsChan {
+		results = append(results, ruleResults...)
 	}
 
 	// Calculate summary
@@ -75,9 +149,24 @@ func (e *Engine) Execute() (*ExecutionResult, error) {
 	}, nil
 }
 
-func (e *Engine) checkRule(ruleID string, activeRule *config.ActiveRule) RuleResult {
+func (e *Engine) checkRule(ruleID string, activeRule *config.ActiveRule) []RuleResult {
 	logger.Verbose(fmt.Sprintf("Checking: %s", ruleID))
 
+	switch {
+	case activeRule.Plugin != nil:
+		return e.plugins.Check(activeRule)
+	case activeRule.Custom != nil && activeRule.Custom.Command != nil:
+		return []RuleResult{e.commands.Run(activeRule)}
+	case activeRule.Custom != nil:
+		return e.scripts.Run(activeRule)
+	case manifestRules[ruleID]:
+		return []RuleResult{e.checkManifest(ruleID, activeRule)}
+	default:
+		return []RuleResult{e.checkPatterns(ruleID, activeRule)}
+	}
+}
+
+func (e *Engine) checkPatterns(ruleID string, activeRule *config.ActiveRule) RuleResult {
 	patterns := config.GetPatterns(activeRule.Metadata.Patterns, e.ctx.ProjectType)
 	if len(patterns) == 0 {
 		logger.Verbose(fmt.Sprintf("No patterns for %s in %s projects", ruleID, e.ctx.ProjectType))
@@ -110,12 +199,24 @@ func (e *Engine) checkRule(ruleID string, activeRule *config.ActiveRule) RuleRes
 	}
 }
 
+// logUnusedPlugins points out plugins that are installed but not enabled
+func (e *Engine) logUnusedPlugins() {
+	for name, path := range DiscoverPlugins(e.ctx.ProjectPath) {
+		if rule, exists := e.rules[name]; exists && rule.Plugin != nil {
+			continue
+		}
+		logger.Verbose(fmt.Sprintf("Plugin %s found at %s but not enabled in custom.plugins", name, path))
+	}
+}
+
 func (e *Engine) calculateSummary(results []RuleResult) Summary {
 	summary := Summary{Total: len(results)}
 
 	for _, result := range results {
 		if result.Passed {
 			summary.Passed++
+		} else if result.Suppressed {
+			summary.Suppressed++
 		} else {
 			switch result.Severity {
 			case config.SeverityError:
@@ -2,18 +2,26 @@ package rules
 
 import (
 	"fmt"
+	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
 	"github.com/m-mdy-m/psx/internal/config"
 	"github.com/m-mdy-m/psx/internal/logger"
+	"github.com/m-mdy-m/psx/internal/managed"
 	"github.com/m-mdy-m/psx/internal/utils"
 )
 
+// keepFile is created in the folders fixes create: the checks want a
+// folder with something in it, and git doesn't track empty folders
+const keepFile = ".gitkeep"
+
 type Fixer struct {
 	ctx       *Context
 	generator *ContentGenerator
 	resolver  *PatternResolver
+	plugins   *PluginRunner
 }
 
 func NewFixer(ctx *Context) *Fixer {
@@ -21,6 +29,7 @@ func NewFixer(ctx *Context) *Fixer {
 		ctx:       ctx,
 		generator: NewContentGenerator(ctx.ProjectInfo, ctx.ProjectType),
 		resolver:  NewPatternResolver(),
+		plugins:   NewPluginRunner(ctx),
 	}
 }
 
@@ -31,7 +40,15 @@ func Fix(cfg *config.Config, fixCtx *FixContext, ruleID string) (*FixResult, err
 		return nil, fmt.Errorf("rule not found: %s", ruleID)
 	}
 
-	return fixer.fix(ruleID, activeRule, fixCtx)
+	mark := fixCtx.Plan.begin(ruleID)
+	result, err := fixer.fix(ruleID, activeRule, fixCtx)
+	if err != nil || result.Error != nil {
+		fixCtx.Plan.discard(mark)
+	}
+	if result != nil {
+		result.Scope = fixCtx.Context.Scope
+	}
+	return result, err
 }
 
 func FixAll(cfg *config.Config, fixCtx *FixContext, failedRules []string) ([]*FixResult, error) {
@@ -44,12 +61,19 @@ func FixAll(cfg *config.Config, fixCtx *FixContext, failedRules []string) ([]*Fi
 			logger.Warning(fmt.Sprintf("Rule not found: %s", ruleID))
 			continue
 		}
+		// a rule that fails leaves nothing in the plan
+		mark := fixCtx.Plan.begin(ruleID)
 		result, err := fixer.fix(ruleID, activeRule, fixCtx)
 		if err != nil {
+			fixCtx.Plan.discard(mark)
 			logger.Warning(fmt.Sprintf("Fix failed for %s: %v", ruleID, err))
 			continue
 		}
+		if result.Error != nil {
+			fixCtx.Plan.discard(mark)
+		}
 
+		result.Scope = fixCtx.Context.Scope
 		results = append(results, result)
 	}
 
@@ -67,6 +91,22 @@ func FixAll(cfg *config.Config, fixCtx *FixContext, failedRules []string) ([]*Fi
 func (f *Fixer) fix(ruleID string, rule *config.ActiveRule, fixCtx *FixContext) (*FixResult, error) {
 	logger.Verbose(fmt.Sprintf("Fixing: %s", ruleID))
 
+	if rule.Plugin != nil {
+		return f.fixPlugin(ruleID, rule, fixCtx)
+	}
+
+	if manifestRules[ruleID] {
+		return f.fixManifest(ruleID, fixCtx)
+	}
+
+	// an existing ignore file is completed rather than skipped
+	if path, missing := f.missingEntries(ruleID, rule); path != "" {
+		if len(missing) == 0 {
+			return &FixResult{RuleID: ruleID, Skipped: true}, nil
+		}
+		return f.fixMerge(ruleID, path, missing, fixCtx)
+	}
+
 	if f.resolver.IsSpecialMultiFileRule(ruleID) || f.needsMultiFileGeneration(ruleID) {
 		multiFiles, err := f.generator.GenerateMultiple(ruleID)
 		if err == nil && len(multiFiles) > 0 {
@@ -129,7 +169,7 @@ func (f *Fixer) fixSinglePattern(ruleID, pattern string, fixCtx *FixContext) (*F
 	if fixCtx.DryRun {
 		changes = f.previewChanges(ruleID, pattern, fullPath, isFolder)
 	} else {
-		changes, err = f.applyChanges(ruleID, pattern, fullPath, isFolder)
+		changes, err = f.applyChanges(ruleID, pattern, fullPath, isFolder, fixCtx.Plan)
 		if err != nil {
 			return &FixResult{
 				RuleID: ruleID,
@@ -168,14 +208,15 @@ func (f *Fixer) fixMultipleFiles(ruleID string, files map[string]string, fixCtx
 				Type:        ChangeCreateFile,
 				Path:        fullPath,
 				Description: fmt.Sprintf("Create %s", relPath),
-				Content:     formatContent(content, 10),
+				Content:     content,
 			})
 		} else {
-			err := utils.CreateFile(fullPath, content)
+			err := fixCtx.Plan.WriteFile(fullPath, []byte(content))
 			if err != nil {
 				errors = append(errors, fmt.Sprintf("%s: %v", relPath, err))
 				continue
 			}
+			fixCtx.Plan.Manage(fullPath, f.managedEntry(ruleID), []byte(content))
 
 			changes = append(changes, Change{
 				Type:        ChangeCreateFile,
@@ -199,6 +240,134 @@ func (f *Fixer) fixMultipleFiles(ruleID string, files map[string]string, fixCtx
 	}, nil
 }
 
+func (f *Fixer) fixPlugin(ruleID string, rule *config.ActiveRule, fixCtx *FixContext) (*FixResult, error) {
+	pluginChanges, err := f.plugins.Changes(rule)
+	if err != nil {
+		return &FixResult{RuleID: ruleID, Error: err}, nil
+	}
+	if len(pluginChanges) == 0 {
+		return &FixResult{RuleID: ruleID, Skipped: true}, nil
+	}
+
+	if fixCtx.Interactive && !fixCtx.DryRun {
+		prompt := fmt.Sprintf("Apply %d changes from plugin %s?", len(pluginChanges), ruleID)
+		if !utils.Prompt(prompt) {
+			return &FixResult{RuleID: ruleID, Skipped: true}, nil
+		}
+	}
+
+	var changes []Change
+	var errors []string
+
+	for _, pc := range keepPluginFolders(pluginChanges) {
+		fullPath, _ := f.plugins.resolve(pc.Path)
+		changeType := ChangeType(pc.Type)
+		description := pc.Description
+		if description == "" {
+			description = fmt.Sprintf("%s %s", changeType, pc.Path)
+		}
+
+		if changeType == ChangeCreateFile && f.shouldSkipPattern(fullPath) {
+			continue
+		}
+		if exists, _ := utils.FileExists(fullPath); changeType == ChangeDeleteFile && !exists {
+			continue
+		}
+
+		if fixCtx.DryRun {
+			change := Change{
+				Type:        changeType,
+				Path:        fullPath,
+				Description: description,
+				Content:     pc.Content,
+			}
+			// the preview diffs against what is there now
+			if changeType != ChangeCreateFolder {
+				if data, err := os.ReadFile(fullPath); err == nil {
+					change.Original = string(data)
+				}
+			}
+			if changeType == ChangeDeleteFile {
+				change.Content = ""
+			}
+			changes = append(changes, change)
+			continue
+		}
+
+		switch changeType {
+		case ChangeCreateFolder:
+			err = fixCtx.Plan.CreateDir(fullPath)
+		case ChangeCreateFile, ChangeModifyFile:
+			err = fixCtx.Plan.WriteFile(fullPath, []byte(pc.Content))
+		case ChangeDeleteFile:
+			err = fixCtx.Plan.DeleteFile(fullPath)
+		default:
+			err = fmt.Errorf("unsupported change type %q", pc.Type)
+		}
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", pc.Path, err))
+			continue
+		}
+
+		changes = append(changes, Change{
+			Type:        changeType,
+			Path:        fullPath,
+			Description: description,
+		})
+	}
+
+	if len(errors) > 0 {
+		return &FixResult{
+			RuleID:  ruleID,
+			Error:   fmt.Errorf("failed to apply some changes: %s", strings.Join(errors, "; ")),
+			Changes: changes,
+		}, nil
+	}
+	if len(changes) == 0 {
+		return &FixResult{RuleID: ruleID, Skipped: true}, nil
+	}
+
+	return &FixResult{
+		RuleID:  ruleID,
+		Fixed:   true,
+		Changes: changes,
+	}, nil
+}
+
+// keepPluginFolders adds a keep file to each folder a plugin creates and
+// writes nothing into, as built-in fixes do
+func keepPluginFolders(changes []PluginChange) []PluginChange {
+	filled := map[string]bool{}
+	for _, pc := range changes {
+		if t := ChangeType(pc.Type); t == ChangeCreateFile || t == ChangeModifyFile {
+			for dir := path.Dir(path.Clean(pc.Path)); dir != "." && dir != "/"; dir = path.Dir(dir) {
+				filled[dir] = true
+			}
+		}
+	}
+
+	result := make([]PluginChange, 0, len(changes))
+	for _, pc := range changes {
+		result = append(result, pc)
+		dir := path.Clean(pc.Path)
+		if ChangeType(pc.Type) == ChangeCreateFolder && !filled[dir] {
+			keep := path.Join(dir, keepFile)
+			result = append(result, PluginChange{
+				Type:        string(ChangeCreateFile),
+				Path:        keep,
+				Description: fmt.Sprintf("Create %s", keep),
+			})
+		}
+	}
+	return result
+}
+
+// managedEntry is how a file generated for ruleID is recorded, so 'psx
+// check' can tell when its template changes
+func (f *Fixer) managedEntry(ruleID string) managed.Entry {
+	return managed.Entry{Template: ruleID, Type: f.ctx.ProjectType, Scope: f.ctx.Scope}
+}
+
 func (f *Fixer) shouldSkipPattern(fullPath string) bool {
 	exists, info := utils.FileExists(fullPath)
 	if !exists {
@@ -234,6 +403,10 @@ func (f *Fixer) previewChanges(ruleID, pattern, fullPath string, isFolder bool)
 			Path:        fullPath,
 			Description: fmt.Sprintf("Create %s", pattern),
 			Content:     "",
+		}, Change{
+			Type:        ChangeCreateFile,
+			Path:        filepath.Join(fullPath, keepFile),
+			Description: fmt.Sprintf("Create %s", filepath.Join(pattern, keepFile)),
 		})
 	} else {
 		content, _ := f.generator.Generate(ruleID, pattern)
@@ -241,26 +414,34 @@ func (f *Fixer) previewChanges(ruleID, pattern, fullPath string, isFolder bool)
 			Type:        ChangeCreateFile,
 			Path:        fullPath,
 			Description: fmt.Sprintf("Create %s", pattern),
-			Content:     formatContent(content, 10),
+			Content:     content,
 		})
 	}
 
 	return changes
 }
 
-func (f *Fixer) applyChanges(ruleID, pattern, fullPath string, isFolder bool) ([]Change, error) {
+func (f *Fixer) applyChanges(ruleID, pattern, fullPath string, isFolder bool, plan *Plan) ([]Change, error) {
 	changes := []Change{}
 
 	if isFolder {
-		err := utils.CreateDir(fullPath)
+		err := plan.CreateDir(fullPath)
 		if err != nil {
 			return nil, err
 		}
+		keep := filepath.Join(fullPath, keepFile)
+		if err := plan.WriteFile(keep, nil); err != nil {
+			return nil, err
+		}
 
 		changes = append(changes, Change{
 			Type:        ChangeCreateFolder,
 			Path:        fullPath,
 			Description: fmt.Sprintf("Created %s", pattern),
+		}, Change{
+			Type:        ChangeCreateFile,
+			Path:        keep,
+			Description: fmt.Sprintf("Created %s", filepath.Join(pattern, keepFile)),
 		})
 	} else {
 		content, err := f.generator.Generate(ruleID, pattern)
@@ -268,10 +449,11 @@ func (f *Fixer) applyChanges(ruleID, pattern, fullPath string, isFolder bool) ([
 			return nil, err
 		}
 
-		err = utils.CreateFile(fullPath, content)
+		err = plan.WriteFile(fullPath, []byte(content))
 		if err != nil {
 			return nil, err
 		}
+		plan.Manage(fullPath, f.managedEntry(ruleID), []byte(content))
 
 		changes = append(changes, Change{
 			Type:        ChangeCreateFile,
@@ -282,18 +464,3 @@ func (f *Fixer) applyChanges(ruleID, pattern, fullPath string, isFolder bool) ([
 
 	return changes, nil
 }
-
-func formatContent(content string, maxLines int) string {
-	if content == "" {
-		return ""
-	}
-
-	lines := strings.Split(content, "\n")
-	if len(lines) <= maxLines {
-		return content
-	}
-
-	preview := strings.Join(lines[:maxLines], "\n")
-	remaining := len(lines) - maxLines
-	return fmt.Sprintf("%s\n... (%d more lines)", preview, remaining)
-}
@@ -0,0 +1,37 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeepPluginFolders(t *testing.T) {
+	changes := []PluginChange{
+		{Type: string(ChangeCreateFolder), Path: "gen"},
+		{Type: string(ChangeCreateFile), Path: "gen/a.txt"},
+		{Type: string(ChangeCreateFolder), Path: "empty/"},
+		{Type: string(ChangeCreateFolder), Path: "docs"},
+		{Type: string(ChangeModifyFile), Path: "docs/api/index.md"},
+		{Type: string(ChangeCreateFolder), Path: "old"},
+		{Type: string(ChangeDeleteFile), Path: "old/notes.txt"},
+	}
+
+	var got []string
+	for _, pc := range keepPluginFolders(changes) {
+		got = append(got, pc.Type+" "+pc.Path)
+	}
+	want := []string{
+		"create_folder gen",
+		"create_file gen/a.txt",
+		"create_folder empty/",
+		"create_file empty/.gitkeep",
+		"create_folder docs",
+		"modify_file docs/api/index.md",
+		"create_folder old",
+		"create_file old/.gitkeep",
+		"delete_file old/notes.txt",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got changes\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,135 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/m-mdy-m/psx/internal/config"
+	"github.com/m-mdy-m/psx/internal/logger"
+	"github.com/m-mdy-m/psx/internal/manifest"
+	"github.com/m-mdy-m/psx/internal/resources"
+	"github.com/m-mdy-m/psx/internal/utils"
+)
+
+// manifestRules check the fields of the project's manifest (see
+// GetManifest) rather than whether a file exists
+var manifestRules = map[string]bool{
+	"package_metadata": true,
+}
+
+// manifestState is what a manifest rule found in the project
+type manifestState struct {
+	path    string // "" when the project type has no manifest
+	data    []byte // nil when the manifest doesn't exist
+	missing []manifest.Field
+}
+
+func loadManifest(ctx *Context) (*manifestState, error) {
+	file, fields := resources.GetManifest(ctx.ProjectInfo, ctx.ProjectType)
+	if file == "" {
+		return &manifestState{}, nil
+	}
+
+	state := &manifestState{path: filepath.Join(ctx.ProjectPath, file)}
+	data, err := os.ReadFile(state.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state.data = data
+
+	wanted := make([]manifest.Field, 0, len(fields))
+	for _, field := range fields {
+		wanted = append(wanted, manifest.Field{Key: field.Key, Value: field.Value})
+	}
+	state.missing, err = manifest.Missing(state.path, data, wanted)
+	return state, err
+}
+
+func (e *Engine) checkManifest(ruleID string, activeRule *config.ActiveRule) RuleResult {
+	result := RuleResult{RuleID: ruleID, Passed: true, Severity: activeRule.Severity, Message: "OK"}
+
+	state, err := loadManifest(e.ctx)
+	switch {
+	case err != nil:
+		result.Passed = false
+		result.Message = err.Error()
+	case state.path == "":
+		result.Message = "Not applicable for this project type"
+	case state.data == nil:
+		// package_manager reports a missing manifest
+		result.Message = fmt.Sprintf("No %s", filepath.Base(state.path))
+	case len(state.missing) > 0:
+		result.Passed = false
+		result.Message = fmt.Sprintf("%s: %s", activeRule.Metadata.Message, fieldKeys(state.missing))
+		result.FixHint = activeRule.Metadata.FixHint
+		result.DocURL = activeRule.Metadata.DocURL
+	}
+	return result
+}
+
+func (f *Fixer) fixManifest(ruleID string, fixCtx *FixContext) (*FixResult, error) {
+	state, err := loadManifest(f.ctx)
+	if err != nil {
+		return &FixResult{RuleID: ruleID, Error: err}, err
+	}
+	if state.data == nil || len(state.missing) == 0 {
+		return &FixResult{RuleID: ruleID, Skipped: true}, nil
+	}
+
+	// fields without a value need project info psx doesn't have
+	fill, manual := []manifest.Field{}, []manifest.Field{}
+	for _, field := range state.missing {
+		if field.Value == nil {
+			manual = append(manual, field)
+		} else {
+			fill = append(fill, field)
+		}
+	}
+	name := filepath.Base(state.path)
+	if len(manual) > 0 {
+		logger.Warning(fmt.Sprintf("Set %s in %s by hand", fieldKeys(manual), name))
+	}
+	if len(fill) == 0 {
+		return &FixResult{RuleID: ruleID, Skipped: true}, nil
+	}
+
+	description := fmt.Sprintf("Add %s to %s", fieldKeys(fill), name)
+	if fixCtx.Interactive && !fixCtx.DryRun {
+		if !utils.Prompt(description + "?") {
+			return &FixResult{RuleID: ruleID, Skipped: true}, nil
+		}
+	}
+
+	after, err := manifest.Add(state.path, state.data, fill)
+	if err != nil {
+		return &FixResult{RuleID: ruleID, Error: err}, err
+	}
+	change := Change{
+		Type:        ChangeModifyFile,
+		Path:        state.path,
+		Description: description,
+		Content:     string(after),
+		Original:    string(state.data),
+	}
+	if !fixCtx.DryRun {
+		if err := fixCtx.Plan.WriteFile(state.path, after); err != nil {
+			return &FixResult{RuleID: ruleID, Error: err}, err
+		}
+		change.Description = "Added" + strings.TrimPrefix(description, "Add")
+	}
+	return &FixResult{RuleID: ruleID, Fixed: true, Changes: []Change{change}}, nil
+}
+
+func fieldKeys(fields []manifest.Field) string {
+	keys := make([]string, 0, len(fields))
+	for _, field := range fields {
+		keys = append(keys, field.Key)
+	}
+	return strings.Join(keys, ", ")
+}
@@ -0,0 +1,149 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/m-mdy-m/psx/internal/config"
+	"github.com/m-mdy-m/psx/internal/resources"
+	"github.com/m-mdy-m/psx/internal/utils"
+)
+
+// MergeMarker heads the section psx appends to an existing ignore file
+const MergeMarker = "# Added by psx"
+
+// mergeTemplates are the rules whose existing file is completed from the
+// template instead of being left alone
+var mergeTemplates = map[string]func(projectType string) string{
+	"gitignore":    resources.GetGitignore,
+	"dockerignore": resources.GetDockerignore,
+}
+
+// MergeableRules lists the active rules whose file exists but is missing
+// entries from the template, so `psx fix` can complete it even though the
+// check passes
+func MergeableRules(cfg *config.Config, ctx *Context) []string {
+	f := NewFixer(ctx)
+	ids := []string{}
+	for id := range mergeTemplates {
+		if _, active := cfg.ActiveRules[id]; !active {
+			continue
+		}
+		if path, missing := f.missingEntries(id, cfg.ActiveRules[id]); path != "" && len(missing) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// missingEntries returns the existing file of a merge rule and the template
+// entries it lacks. path is "" when the rule has no file to merge into
+func (f *Fixer) missingEntries(ruleID string, rule *config.ActiveRule) (string, []string) {
+	template, ok := mergeTemplates[ruleID]
+	if !ok {
+		return "", nil
+	}
+	patterns := config.GetPatterns(rule.Metadata.Patterns, f.ctx.ProjectType)
+	if len(patterns) == 0 {
+		return "", nil
+	}
+
+	path := filepath.Join(f.ctx.ProjectPath, patterns[0])
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 {
+		return "", nil
+	}
+	return path, missingIgnoreEntries(string(data), template(f.ctx.ProjectType))
+}
+
+// missingIgnoreEntries lists the entries of template that existing doesn't
+// have. "coverage", "/coverage" and "coverage/" count as the same entry
+func missingIgnoreEntries(existing, template string) []string {
+	have := map[string]bool{}
+	for _, entry := range ignoreEntries(existing) {
+		have[normalizeIgnoreEntry(entry)] = true
+	}
+
+	missing := []string{}
+	for _, entry := range ignoreEntries(template) {
+		key := normalizeIgnoreEntry(entry)
+		if !have[key] {
+			have[key] = true
+			missing = append(missing, entry)
+		}
+	}
+	return missing
+}
+
+func ignoreEntries(content string) []string {
+	entries := []string{}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			entries = append(entries, line)
+		}
+	}
+	return entries
+}
+
+func normalizeIgnoreEntry(entry string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(entry, "/"), "/")
+}
+
+// mergeIgnoreFile adds entries to an ignore file under MergeMarker. A file
+// that already has the section gets them at the end of it
+func mergeIgnoreFile(content string, entries []string) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) != MergeMarker {
+			continue
+		}
+		end := i + 1
+		for end < len(lines) && strings.TrimSpace(lines[end]) != "" {
+			end++
+		}
+		merged := append(append(append([]string{}, lines[:end]...), entries...), lines[end:]...)
+		return strings.Join(merged, "\n") + "\n"
+	}
+
+	return strings.Join(lines, "\n") + "\n\n" + MergeMarker + "\n" + strings.Join(entries, "\n") + "\n"
+}
+
+func (f *Fixer) fixMerge(ruleID, path string, missing []string, fixCtx *FixContext) (*FixResult, error) {
+	name, _ := filepath.Rel(f.ctx.ProjectPath, path)
+	description := fmt.Sprintf("Add %d missing entries to %s", len(missing), name)
+	if len(missing) == 1 {
+		description = fmt.Sprintf("Add %s to %s", missing[0], name)
+	}
+
+	if fixCtx.Interactive && !fixCtx.DryRun {
+		if !utils.Prompt(description + "?") {
+			return &FixResult{RuleID: ruleID, Skipped: true}, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return &FixResult{RuleID: ruleID, Error: err}, err
+	}
+	after := []byte(mergeIgnoreFile(string(data), missing))
+	change := Change{
+		Type:        ChangeModifyFile,
+		Path:        path,
+		Description: description,
+		Content:     string(after),
+		Original:    string(data),
+	}
+	if !fixCtx.DryRun {
+		if err := fixCtx.Plan.WriteFile(path, after); err != nil {
+			return &FixResult{RuleID: ruleID, Error: err}, err
+		}
+		change.Description = "Added" + strings.TrimPrefix(description, "Add")
+	}
+	return &FixResult{RuleID: ruleID, Fixed: true, Changes: []Change{change}}, nil
+}
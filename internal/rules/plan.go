@@ -0,0 +1,239 @@
+package rules
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/m-mdy-m/psx/internal/history"
+	"github.com/m-mdy-m/psx/internal/managed"
+	"github.com/m-mdy-m/psx/internal/utils"
+)
+
+// Plan collects the writes of a fix run. Nothing touches the project until
+// Apply, so a run makes either all of its changes or none of them
+type Plan struct {
+	root    string
+	writes  []history.Write
+	owners  []string       // rule that planned each write
+	index   map[string]int // path -> position in writes
+	rule    string         // rule being planned
+	managed map[string]generated
+}
+
+// generated is a file written from a template, recorded in the managed
+// manifest when the plan is applied
+type generated struct {
+	entry   managed.Entry
+	content []byte
+}
+
+// NewPlan starts the plan of a fix run in the project at root
+func NewPlan(root string) *Plan {
+	return &Plan{root: root, index: map[string]int{}, managed: map[string]generated{}}
+}
+
+// WriteFile plans writing content to path. Two rules may plan the same
+// content for a file, but not different content
+func (p *Plan) WriteFile(path string, content []byte) error {
+	if err := p.checkParents(path); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return fmt.Errorf("%s is a folder", filepath.Base(path))
+	}
+
+	if i, ok := p.index[path]; ok {
+		planned := p.writes[i]
+		switch {
+		case planned.Dir, planned.Delete:
+			return p.conflict(i)
+		case bytes.Equal(planned.Content, content):
+			return nil
+		case p.owners[i] != p.rule:
+			return p.conflict(i)
+		}
+		p.writes[i].Content = content
+		return nil
+	}
+
+	p.add(history.Write{Path: path, Content: content})
+	return nil
+}
+
+// CreateDir plans creating path and its missing parents
+func (p *Plan) CreateDir(path string) error {
+	if err := p.checkParents(path); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		return fmt.Errorf("%s is a file", filepath.Base(path))
+	}
+
+	if i, ok := p.index[path]; ok {
+		if !p.writes[i].Dir {
+			return p.conflict(i)
+		}
+		return nil
+	}
+
+	p.add(history.Write{Path: path, Dir: true})
+	return nil
+}
+
+// DeleteFile plans removing the file at path
+func (p *Plan) DeleteFile(path string) error {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return fmt.Errorf("%s is a folder", filepath.Base(path))
+	}
+
+	if i, ok := p.index[path]; ok {
+		if !p.writes[i].Delete {
+			return p.conflict(i)
+		}
+		return nil
+	}
+
+	p.add(history.Write{Path: path, Delete: true})
+	return nil
+}
+
+// Manage records that the file planned at path was generated from the
+// template of entry with content, which may differ from what is written
+// when local edits were merged in
+func (p *Plan) Manage(path string, entry managed.Entry, content []byte) {
+	if p == nil {
+		return
+	}
+	p.managed[path] = generated{entry: entry, content: content}
+}
+
+// Empty reports whether nothing is planned
+func (p *Plan) Empty() bool {
+	return p == nil || len(p.writes) == 0
+}
+
+// Apply makes the planned writes through the journal, which rolls back
+// everything when one of them fails
+func (p *Plan) Apply(journal *history.Journal) error {
+	if p.Empty() {
+		return nil
+	}
+	if err := p.planManaged(); err != nil {
+		return err
+	}
+	return journal.Commit(p.writes)
+}
+
+// planManaged adds the managed manifest, and the generated content a later
+// update merges against, to the writes, so 'psx undo' reverts them too
+func (p *Plan) planManaged() error {
+	if len(p.managed) == 0 {
+		return nil
+	}
+	m, err := managed.Load(p.root)
+	if err != nil {
+		return err
+	}
+	maps.Copy(m.Answers, utils.Answers())
+
+	replaced := []string{}
+	today := time.Now().Format(managed.DateFormat)
+	for path, g := range p.managed {
+		entry := g.entry
+		entry.Path = p.relPath(path)
+		entry.Hash = managed.Hash(g.content)
+		if entry.Date == "" {
+			entry.Date = today
+		}
+		if old := m.Find(entry.Path); old != nil && old.Hash != entry.Hash {
+			replaced = append(replaced, old.Hash)
+		}
+		m.Set(entry)
+
+		base := managed.BasePath(p.root, entry.Hash)
+		if _, err := os.Stat(base); errors.Is(err, os.ErrNotExist) {
+			if _, planned := p.index[base]; !planned {
+				p.add(history.Write{Path: base, Content: g.content})
+			}
+		}
+	}
+
+	// drop the content no entry refers to any more
+	for _, hash := range replaced {
+		base := managed.BasePath(p.root, hash)
+		if _, err := os.Stat(base); err == nil && !m.References(hash) {
+			p.add(history.Write{Path: base, Delete: true})
+		}
+	}
+
+	data, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+	p.add(history.Write{Path: filepath.Join(p.root, filepath.FromSlash(managed.File)), Content: data})
+	return nil
+}
+
+func (p *Plan) relPath(path string) string {
+	rel, err := filepath.Rel(p.root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// begin starts planning the writes of a rule and returns the mark to
+// discard them at when the rule fails
+func (p *Plan) begin(ruleID string) int {
+	if p == nil {
+		return 0
+	}
+	p.rule = ruleID
+	return len(p.writes)
+}
+
+// discard drops the writes planned since mark
+func (p *Plan) discard(mark int) {
+	if p == nil || mark >= len(p.writes) {
+		return
+	}
+	for _, w := range p.writes[mark:] {
+		delete(p.index, w.Path)
+		delete(p.managed, w.Path)
+	}
+	p.writes = p.writes[:mark]
+	p.owners = p.owners[:mark]
+}
+
+func (p *Plan) add(w history.Write) {
+	p.index[w.Path] = len(p.writes)
+	p.writes = append(p.writes, w)
+	p.owners = append(p.owners, p.rule)
+}
+
+// checkParents makes sure no folder above path is planned as a file
+func (p *Plan) checkParents(path string) error {
+	for dir := filepath.Dir(path); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if i, ok := p.index[dir]; ok && !p.writes[i].Dir {
+			return p.conflict(i)
+		}
+	}
+	return nil
+}
+
+func (p *Plan) conflict(i int) error {
+	w := p.writes[i]
+	action := "writes"
+	switch {
+	case w.Dir:
+		action = "creates the folder"
+	case w.Delete:
+		action = "deletes"
+	}
+	return fmt.Errorf("conflicts with %s, which %s %s", p.owners[i], action, filepath.Base(w.Path))
+}
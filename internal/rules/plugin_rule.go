@@ -0,0 +1,358 @@
+package rules
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/m-mdy-m/psx/internal/config"
+	"github.com/m-mdy-m/psx/internal/logger"
+	"github.com/m-mdy-m/psx/internal/utils"
+)
+
+// Plugin protocol
+//
+// psx starts the plugin executable in the project root, writes a single
+// PluginRequest as JSON to its stdin and reads a single PluginResponse from
+// its stdout. The request lists every protocol version psx understands; the
+// plugin answers with the one it picked. Anything written to stderr is only
+// used in error messages.
+const (
+	PluginProtocolVersion = 1
+	PluginPrefix          = "psx-rule-"
+	PluginDir             = ".psx/plugins"
+
+	defaultPluginTimeout = 30 * time.Second
+)
+
+var supportedPluginVersions = []int{PluginProtocolVersion}
+
+const (
+	PluginModeCheck = "check"
+	PluginModeFix   = "fix"
+)
+
+type PluginRequest struct {
+	ProtocolVersions []int              `json:"protocol_versions"`
+	Mode             string             `json:"mode"`
+	Rule             string             `json:"rule"`
+	ProjectPath      string             `json:"project_path"`
+	ProjectType      string             `json:"project_type"`
+	ProjectInfo      *PluginProjectInfo `json:"project_info"`
+	Config           map[string]any     `json:"config"`
+}
+
+type PluginProjectInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Author      string `json:"author"`
+	Email       string `json:"email"`
+	GitHubUser  string `json:"github_user"`
+	RepoName    string `json:"repo_name"`
+	RepoURL     string `json:"repo_url"`
+	License     string `json:"license"`
+}
+
+type PluginResponse struct {
+	ProtocolVersion int             `json:"protocol_version"`
+	Findings        []PluginFinding `json:"findings"`
+	Changes         []PluginChange  `json:"changes"`
+	Error           string          `json:"error,omitempty"`
+}
+
+// PluginFinding mirrors RuleResult
+type PluginFinding struct {
+	RuleID   string `json:"rule_id"`
+	Passed   bool   `json:"passed"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	FixHint  string `json:"fix_hint"`
+	DocURL   string `json:"doc_url"`
+	Path     string `json:"path"`
+}
+
+// PluginChange mirrors Change; Path is relative to the project root
+type PluginChange struct {
+	Type        string `json:"type"`
+	Path        string `json:"path"`
+	Description string `json:"description"`
+	Content     string `json:"content"`
+}
+
+type PluginRunner struct {
+	ctx *Context
+}
+
+func NewPluginRunner(ctx *Context) *PluginRunner {
+	return &PluginRunner{ctx: ctx}
+}
+
+// Check runs the plugin in check mode and turns its findings into results
+func (p *PluginRunner) Check(rule *config.ActiveRule) []RuleResult {
+	resp, err := p.call(rule.Plugin, PluginModeCheck)
+	if err != nil {
+		logger.Verbose(fmt.Sprintf("Plugin %s: %v", rule.ID, err))
+		return []RuleResult{{
+			RuleID:   rule.ID,
+			Passed:   false,
+			Severity: rule.Severity,
+			Message:  fmt.Sprintf("Plugin error: %v", err),
+		}}
+	}
+
+	if len(resp.Findings) == 0 {
+		return []RuleResult{{
+			RuleID:   rule.ID,
+			Passed:   true,
+			Severity: rule.Severity,
+			Message:  "OK",
+		}}
+	}
+
+	results := make([]RuleResult, 0, len(resp.Findings))
+	for _, finding := range resp.Findings {
+		results = append(results, p.toResult(rule, finding))
+	}
+	return results
+}
+
+// Changes runs the plugin in fix mode and returns the changes it proposes.
+// The plugin never writes to the project itself; psx applies the changes
+func (p *PluginRunner) Changes(rule *config.ActiveRule) ([]PluginChange, error) {
+	resp, err := p.call(rule.Plugin, PluginModeFix)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range resp.Changes {
+		if _, err := p.resolve(change.Path); err != nil {
+			return nil, err
+		}
+	}
+	return resp.Changes, nil
+}
+
+func (p *PluginRunner) toResult(rule *config.ActiveRule, finding PluginFinding) RuleResult {
+	// Severity set in psx.yml wins over whatever the plugin suggests
+	severity := rule.Severity
+	if rule.Plugin.Severity == nil {
+		if s := config.Severity(finding.Severity); s.IsValid() {
+			severity = s
+		}
+	}
+
+	message := finding.Message
+	if message == "" && !finding.Passed {
+		message = rule.Metadata.Message
+	}
+	if finding.Passed {
+		message = "OK"
+	} else if finding.RuleID != "" && finding.RuleID != rule.ID {
+		message = fmt.Sprintf("[%s] %s", finding.RuleID, message)
+	}
+
+	return RuleResult{
+		RuleID:   rule.ID,
+		Passed:   finding.Passed,
+		Severity: severity,
+		Message:  message,
+		FixHint:  finding.FixHint,
+		DocURL:   finding.DocURL,
+		Path:     finding.Path,
+	}
+}
+
+func (p *PluginRunner) call(plugin *config.PluginConfig, mode string) (*PluginResponse, error) {
+	executable, err := FindPlugin(p.ctx.ProjectPath, plugin)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := defaultPluginTimeout
+	if plugin.Timeout != "" {
+		if d, err := time.ParseDuration(plugin.Timeout); err == nil && d > 0 {
+			timeout = d
+		}
+	}
+
+	request, err := json.Marshal(p.request(plugin, mode))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	runCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(runCtx, executable)
+	cmd.Dir = p.ctx.ProjectPath
+	cmd.WaitDelay = processWaitDelay
+	cmd.Env = append(os.Environ(), fmt.Sprintf("PSX_PLUGIN_PROTOCOL=%d", PluginProtocolVersion))
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	logger.Verbose(fmt.Sprintf("Running plugin %s (%s mode): %s", plugin.Name, mode, executable))
+	err = cmd.Run()
+	if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w%s", err, stderrSuffix(stderr.String()))
+	}
+
+	var resp PluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("invalid response: %w%s", err, stderrSuffix(stderr.String()))
+	}
+	if !isSupportedPluginVersion(resp.ProtocolVersion) {
+		return nil, fmt.Errorf("plugin speaks protocol version %d, psx supports %v",
+			resp.ProtocolVersion, supportedPluginVersions)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return &resp, nil
+}
+
+func (p *PluginRunner) request(plugin *config.PluginConfig, mode string) PluginRequest {
+	req := PluginRequest{
+		ProtocolVersions: supportedPluginVersions,
+		Mode:             mode,
+		Rule:             plugin.Name,
+		ProjectPath:      p.ctx.ProjectPath,
+		ProjectType:      p.ctx.ProjectType,
+		Config:           plugin.Config,
+	}
+	if info := p.ctx.ProjectInfo; info != nil {
+		req.ProjectInfo = &PluginProjectInfo{
+			Name:        info.Name,
+			Description: info.Description,
+			Author:      info.Author,
+			Email:       info.Email,
+			GitHubUser:  info.GitHubUser,
+			RepoName:    info.RepoName,
+			RepoURL:     info.RepoURL,
+			License:     info.License,
+		}
+	}
+	if req.Config == nil {
+		req.Config = map[string]any{}
+	}
+	return req
+}
+
+// resolve maps a plugin-supplied path onto the project root
+func (p *PluginRunner) resolve(rel string) (string, error) {
+	if rel == "" || filepath.IsAbs(rel) {
+		return "", fmt.Errorf("plugin change path must be relative: %q", rel)
+	}
+	full := filepath.Join(p.ctx.ProjectPath, filepath.FromSlash(rel))
+	check, err := filepath.Rel(p.ctx.ProjectPath, full)
+	if err != nil || check == ".." || strings.HasPrefix(check, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("plugin change escapes the project: %s", rel)
+	}
+	return full, nil
+}
+
+// FindPlugin resolves the executable for a plugin: an explicit path first,
+// then .psx/plugins/ in the project, then psx-rule-<name> on $PATH
+func FindPlugin(projectPath string, plugin *config.PluginConfig) (string, error) {
+	if plugin.Path != "" {
+		path := plugin.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(projectPath, path)
+		}
+		if !isExecutable(path) {
+			return "", fmt.Errorf("plugin %s: %s is not an executable file", plugin.Name, plugin.Path)
+		}
+		return path, nil
+	}
+
+	for _, name := range []string{PluginPrefix + plugin.Name, plugin.Name} {
+		path := filepath.Join(projectPath, PluginDir, name)
+		if isExecutable(path) {
+			return path, nil
+		}
+		if runtime.GOOS == "windows" && isExecutable(path+".exe") {
+			return path + ".exe", nil
+		}
+	}
+
+	if path, err := exec.LookPath(PluginPrefix + plugin.Name); err == nil {
+		return path, nil
+	}
+
+	return "", fmt.Errorf("plugin %s not found in %s or as %s%s on $PATH",
+		plugin.Name, PluginDir, PluginPrefix, plugin.Name)
+}
+
+// DiscoverPlugins lists plugin executables available to a project, keyed by
+// plugin name. Plugins in .psx/plugins/ shadow the ones on $PATH
+func DiscoverPlugins(projectPath string) map[string]string {
+	found := map[string]string{}
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		addPlugins(found, dir, true)
+	}
+	addPlugins(found, filepath.Join(projectPath, PluginDir), false)
+
+	return found
+}
+
+func addPlugins(found map[string]string, dir string, prefixed bool) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if prefixed && !strings.HasPrefix(name, PluginPrefix) {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if !isExecutable(path) {
+			continue
+		}
+		name = strings.TrimSuffix(strings.TrimPrefix(name, PluginPrefix), ".exe")
+		if _, exists := found[name]; exists && prefixed {
+			continue
+		}
+		found[name] = path
+	}
+}
+
+func isExecutable(path string) bool {
+	exists, info := utils.FileExists(path)
+	if !exists || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true
+	}
+	return info.Mode()&0111 != 0
+}
+
+func isSupportedPluginVersion(version int) bool {
+	for _, v := range supportedPluginVersions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+func stderrSuffix(stderr string) string {
+	stderr = strings.TrimSpace(stderr)
+	if stderr == "" {
+		return ""
+	}
+	return ": " + stderr
+}
@@ -0,0 +1,389 @@
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+
+	"github.com/m-mdy-m/psx/internal/config"
+	"github.com/m-mdy-m/psx/internal/logger"
+	"github.com/m-mdy-m/psx/internal/utils"
+)
+
+const (
+	scriptMaxSteps = 10_000_000
+	scriptTimeout  = 10 * time.Second
+	scriptMaxRead  = 10 << 20
+)
+
+// ScriptRunner evaluates Starlark rules. Scripts only get a read-only view
+// of the project: every path they pass in is resolved inside ProjectPath
+type ScriptRunner struct {
+	ctx *Context
+}
+
+func NewScriptRunner(ctx *Context) *ScriptRunner {
+	return &ScriptRunner{ctx: ctx}
+}
+
+// Run executes the rule's check() function. It returns None or True when the
+// rule passes, False to fail with the rule message, a string to fail with a
+// custom message, or a list of strings to report several findings
+func (s *ScriptRunner) Run(rule *config.ActiveRule) []RuleResult {
+	value, err := s.eval(rule.Custom)
+	if err != nil {
+		logger.Verbose(fmt.Sprintf("Script rule %s: %v", rule.ID, err))
+		return []RuleResult{s.failed(rule, fmt.Sprintf("Script error: %v", err))}
+	}
+
+	switch v := value.(type) {
+	case starlark.NoneType:
+		return []RuleResult{s.passed(rule)}
+	case starlark.Bool:
+		if v {
+			return []RuleResult{s.passed(rule)}
+		}
+		return []RuleResult{s.failed(rule, rule.Metadata.Message)}
+	case starlark.String:
+		return []RuleResult{s.failed(rule, string(v))}
+	case *starlark.List:
+		if v.Len() == 0 {
+			return []RuleResult{s.passed(rule)}
+		}
+		results := make([]RuleResult, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			message, ok := starlark.AsString(v.Index(i))
+			if !ok {
+				message = v.Index(i).String()
+			}
+			results = append(results, s.failed(rule, message))
+		}
+		return results
+	default:
+		return []RuleResult{s.failed(rule, fmt.Sprintf("Script error: check() returned unsupported %s", value.Type()))}
+	}
+}
+
+func (s *ScriptRunner) eval(custom *config.CustomRule) (starlark.Value, error) {
+	filename := custom.ID + ".star"
+	var src any = custom.Script
+
+	if custom.ScriptFile != "" {
+		path, err := s.resolve(custom.ScriptFile)
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read script: %w", err)
+		}
+		filename = custom.ScriptFile
+		src = data
+	}
+
+	thread := &starlark.Thread{
+		Name:  custom.ID,
+		Print: func(_ *starlark.Thread, msg string) { logger.Verbose(fmt.Sprintf("[%s] %s", custom.ID, msg)) },
+		Load: func(_ *starlark.Thread, module string) (starlark.StringDict, error) {
+			return nil, fmt.Errorf("load() is not available in psx rules")
+		},
+	}
+	thread.SetMaxExecutionSteps(scriptMaxSteps)
+	timer := time.AfterFunc(scriptTimeout, func() {
+		thread.Cancel(fmt.Sprintf("timed out after %s", scriptTimeout))
+	})
+	defer timer.Stop()
+
+	globals, err := starlark.ExecFile(thread, filename, src, s.predeclared())
+	if err != nil {
+		return nil, err
+	}
+
+	check, ok := globals["check"].(starlark.Callable)
+	if !ok {
+		return nil, fmt.Errorf("script must define a check() function")
+	}
+	return starlark.Call(thread, check, nil, nil)
+}
+
+func (s *ScriptRunner) predeclared() starlark.StringDict {
+	info := s.ctx.ProjectInfo
+	project := starlark.StringDict{
+		"type": starlark.String(s.ctx.ProjectType),
+	}
+	if info != nil {
+		project["name"] = starlark.String(info.Name)
+		project["description"] = starlark.String(info.Description)
+		project["author"] = starlark.String(info.Author)
+		project["email"] = starlark.String(info.Email)
+		project["github_user"] = starlark.String(info.GitHubUser)
+		project["repo_name"] = starlark.String(info.RepoName)
+		project["repo_url"] = starlark.String(info.RepoURL)
+		project["license"] = starlark.String(info.License)
+	}
+
+	fs := &starlarkstruct.Module{
+		Name: "fs",
+		Members: starlark.StringDict{
+			"glob":   starlark.NewBuiltin("glob", s.fsGlob),
+			"exists": starlark.NewBuiltin("exists", s.fsExists),
+			"is_dir": starlark.NewBuiltin("is_dir", s.fsIsDir),
+			"list":   starlark.NewBuiltin("list", s.fsList),
+			"read":   starlark.NewBuiltin("read", s.fsRead),
+			"json":   starlark.NewBuiltin("json", s.fsJSON),
+			"yaml":   starlark.NewBuiltin("yaml", s.fsYAML),
+		},
+	}
+
+	return starlark.StringDict{
+		"fs":      fs,
+		"project": starlarkstruct.FromStringDict(starlarkstruct.Default, project),
+	}
+}
+
+// resolve maps a script-supplied path onto the project, refusing anything
+// that would escape the project root, including through a symlink
+func (s *ScriptRunner) resolve(rel string) (string, error) {
+	if filepath.IsAbs(rel) {
+		return "", fmt.Errorf("absolute paths are not allowed: %s", rel)
+	}
+	full := filepath.Join(s.ctx.ProjectPath, filepath.FromSlash(rel))
+	if !isWithin(s.ctx.ProjectPath, full) {
+		return "", fmt.Errorf("path escapes the project: %s", rel)
+	}
+
+	root, err := filepath.EvalSymlinks(s.ctx.ProjectPath)
+	if err != nil {
+		return "", err
+	}
+	target, err := evalSymlinks(full)
+	if err != nil || !isWithin(root, target) {
+		return "", fmt.Errorf("path escapes the project: %s", rel)
+	}
+	return full, nil
+}
+
+// evalSymlinks is filepath.EvalSymlinks for a path that may not exist yet:
+// the symlinks in the part of it that exists are resolved
+func evalSymlinks(path string) (string, error) {
+	rest := ""
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(resolved, rest), nil
+		}
+		parent := filepath.Dir(path)
+		if !errors.Is(err, os.ErrNotExist) || parent == path {
+			return "", err
+		}
+		rest = filepath.Join(filepath.Base(path), rest)
+		path = parent
+	}
+}
+
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (s *ScriptRunner) fsGlob(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var pattern string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "pattern", &pattern); err != nil {
+		return nil, err
+	}
+	if _, err := s.resolve(pattern); err != nil {
+		return nil, err
+	}
+	matches, err := utils.Glob(s.ctx.ProjectPath, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	// a pattern can reach through a symlinked folder; leave out what it
+	// finds outside the project
+	inside := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if _, err := s.resolve(match); err == nil {
+			inside = append(inside, match)
+		}
+	}
+	sort.Strings(inside)
+	return toStarlarkList(inside), nil
+}
+
+func (s *ScriptRunner) fsExists(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var rel string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "path", &rel); err != nil {
+		return nil, err
+	}
+	path, err := s.resolve(rel)
+	if err != nil {
+		return nil, err
+	}
+	exists, _ := utils.FileExists(path)
+	return starlark.Bool(exists), nil
+}
+
+func (s *ScriptRunner) fsIsDir(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var rel string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "path", &rel); err != nil {
+		return nil, err
+	}
+	path, err := s.resolve(rel)
+	if err != nil {
+		return nil, err
+	}
+	exists, info := utils.FileExists(path)
+	return starlark.Bool(exists && info.IsDir()), nil
+}
+
+func (s *ScriptRunner) fsList(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	rel := "."
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "path?", &rel); err != nil {
+		return nil, err
+	}
+	path, err := s.resolve(rel)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return toStarlarkList(names), nil
+}
+
+func (s *ScriptRunner) fsRead(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	data, err := s.readArg(b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return starlark.String(data), nil
+}
+
+func (s *ScriptRunner) fsJSON(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	data, err := s.readArg(b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	return toStarlark(value)
+}
+
+func (s *ScriptRunner) fsYAML(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	data, err := s.readArg(b, args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("%s: %w", b.Name(), err)
+	}
+	return toStarlark(value)
+}
+
+func (s *ScriptRunner) readArg(b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) ([]byte, error) {
+	var rel string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "path", &rel); err != nil {
+		return nil, err
+	}
+	path, err := s.resolve(rel)
+	if err != nil {
+		return nil, err
+	}
+	exists, info := utils.FileExists(path)
+	if !exists || info.IsDir() {
+		return nil, fmt.Errorf("%s: no such file: %s", b.Name(), rel)
+	}
+	if info.Size() > scriptMaxRead {
+		return nil, fmt.Errorf("%s: %s is too large", b.Name(), rel)
+	}
+	return os.ReadFile(path)
+}
+
+func (s *ScriptRunner) passed(rule *config.ActiveRule) RuleResult {
+	return RuleResult{
+		RuleID:   rule.ID,
+		Passed:   true,
+		Severity: rule.Severity,
+		Message:  "OK",
+	}
+}
+
+func (s *ScriptRunner) failed(rule *config.ActiveRule, message string) RuleResult {
+	return RuleResult{
+		RuleID:   rule.ID,
+		Passed:   false,
+		Severity: rule.Severity,
+		Message:  message,
+		FixHint:  rule.Metadata.FixHint,
+		DocURL:   rule.Metadata.DocURL,
+	}
+}
+
+func toStarlarkList(items []string) *starlark.List {
+	values := make([]starlark.Value, 0, len(items))
+	for _, item := range items {
+		values = append(values, starlark.String(item))
+	}
+	return starlark.NewList(values)
+}
+
+// toStarlark converts decoded JSON/YAML data into Starlark values
+func toStarlark(value any) (starlark.Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return starlark.None, nil
+	case bool:
+		return starlark.Bool(v), nil
+	case string:
+		return starlark.String(v), nil
+	case int:
+		return starlark.MakeInt(v), nil
+	case int64:
+		return starlark.MakeInt64(v), nil
+	case uint64:
+		return starlark.MakeUint64(v), nil
+	case float64:
+		return starlark.Float(v), nil
+	case []any:
+		items := make([]starlark.Value, 0, len(v))
+		for _, item := range v {
+			converted, err := toStarlark(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, converted)
+		}
+		return starlark.NewList(items), nil
+	case map[string]any:
+		dict := starlark.NewDict(len(v))
+		for key, item := range v {
+			converted, err := toStarlark(item)
+			if err != nil {
+				return nil, err
+			}
+			if err := dict.SetKey(starlark.String(key), converted); err != nil {
+				return nil, err
+			}
+		}
+		return dict, nil
+	default:
+		return starlark.String(fmt.Sprint(v)), nil
+	}
+}
@@ -0,0 +1,53 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScriptResolve(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		"out":         outside,
+		"docs/secret": filepath.Join(outside, "secret"),
+		"inner":       filepath.Join(root, "docs"),
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, filepath.FromSlash(link))); err != nil {
+			t.Skipf("symlinks not supported: %v", err)
+		}
+	}
+
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{"docs", true},
+		{"docs/missing/file.md", true},
+		{"inner", true},
+		{"inner/file.md", true},
+		{"../x", false},
+		{"docs/../../x", false},
+		{"/etc/passwd", false},
+		{"out", false},
+		{"out/secret", false},
+		{"out/missing", false},
+		{"docs/secret", false},
+	}
+
+	s := NewScriptRunner(&Context{ProjectPath: root})
+	for _, tt := range tests {
+		_, err := s.resolve(tt.path)
+		if ok := err == nil; ok != tt.ok {
+			t.Errorf("resolve(%q) allowed = %v, want %v (err: %v)", tt.path, ok, tt.ok, err)
+		}
+	}
+}
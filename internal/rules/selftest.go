@@ -0,0 +1,158 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/m-mdy-m/psx/internal/config"
+	"github.com/m-mdy-m/psx/internal/history"
+	"github.com/m-mdy-m/psx/internal/resources"
+)
+
+// SelfTestStatus is how the fix of a rule did in a self-test
+type SelfTestStatus string
+
+const (
+	SelfTestPassed       SelfTestStatus = "passed"         // the rule passes after the fix
+	SelfTestFailing      SelfTestStatus = "failing"        // fixed, but the rule still fails
+	SelfTestNotFixed     SelfTestStatus = "not fixed"      // the rule fails and the fix did nothing
+	SelfTestError        SelfTestStatus = "error"          // the fix failed
+	SelfTestNothingToFix SelfTestStatus = "nothing to fix" // the rule passes in an empty project
+)
+
+// SelfTestResult is how the fix of a built-in rule did in an empty project
+type SelfTestResult struct {
+	ProjectType string
+	RuleID      string
+	Status      SelfTestStatus
+	Detail      string
+}
+
+// Failed reports whether the fix didn't make its rule pass
+func (r SelfTestResult) Failed() bool {
+	return r.Status == SelfTestFailing || r.Status == SelfTestNotFixed || r.Status == SelfTestError
+}
+
+// manifestSeeds are what a manifest starts as in a self-test, so the
+// package_metadata fix has one to fill in
+var manifestSeeds = map[string]string{
+	".json": "{}\n",
+}
+
+// SelfTest runs the fix of every built-in rule in an empty project of
+// projectType, each in its own temporary directory, and checks the rule
+// again afterwards
+func SelfTest(projectType string, info *resources.ProjectInfo) ([]SelfTestResult, error) {
+	metadata := config.GetRulesMetadata()
+	ids := make([]string, 0, len(metadata.Rules))
+	for id := range metadata.Rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	results := make([]SelfTestResult, 0, len(ids))
+	for _, id := range ids {
+		rule := &config.ActiveRule{
+			ID:       id,
+			Metadata: metadata.Rules[id],
+			Severity: metadata.Rules[id].DefaultSeverity,
+			Source:   config.MetadataSource,
+		}
+		result, err := selfTestRule(projectType, rule, info)
+		if err != nil {
+			return results, fmt.Errorf("%s: %w", id, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func selfTestRule(projectType string, rule *config.ActiveRule, info *resources.ProjectInfo) (SelfTestResult, error) {
+	result := SelfTestResult{ProjectType: projectType, RuleID: rule.ID}
+
+	dir, err := os.MkdirTemp("", "psx-selftest-")
+	if err != nil {
+		return result, err
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := &config.Config{
+		Project:     config.ProjectType{Type: projectType},
+		Path:        dir,
+		ActiveRules: map[string]*config.ActiveRule{rule.ID: rule},
+	}
+	ctx := &Context{ProjectPath: dir, ProjectType: projectType, ProjectInfo: info, Config: cfg}
+
+	if manifestRules[rule.ID] {
+		if file, _ := resources.GetManifest(info, projectType); file != "" {
+			seed := manifestSeeds[filepath.Ext(file)]
+			if err := os.WriteFile(filepath.Join(dir, file), []byte(seed), 0644); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	engine := NewEngine(cfg, ctx)
+	if before := failingChecks(engine.checkRule(rule.ID, rule)); len(before) == 0 {
+		result.Status = SelfTestNothingToFix
+		return result, nil
+	}
+
+	fixCtx := &FixContext{
+		Context: ctx,
+		Plan:    NewPlan(dir),
+		Journal: history.Begin(dir, "psx selftest", false),
+	}
+	fix, err := Fix(cfg, fixCtx, rule.ID)
+	if err == nil && fix.Error == nil {
+		err = fixCtx.Plan.Apply(fixCtx.Journal)
+	}
+	switch {
+	case err != nil:
+		result.Status, result.Detail = SelfTestError, err.Error()
+		return result, nil
+	case fix.Error != nil:
+		result.Status, result.Detail = SelfTestError, fix.Error.Error()
+		return result, nil
+	case !fix.Fixed:
+		result.Status, result.Detail = SelfTestNotFixed, "the fix was skipped"
+		return result, nil
+	}
+
+	if after := failingChecks(engine.checkRule(rule.ID, rule)); len(after) > 0 {
+		result.Status, result.Detail = SelfTestFailing, strings.Join(after, "; ")
+		result.Detail += " - wrote " + strings.Join(changedFiles(dir, fix.Changes), ", ")
+		return result, nil
+	}
+	result.Status = SelfTestPassed
+	return result, nil
+}
+
+func failingChecks(results []RuleResult) []string {
+	failing := []string{}
+	for _, result := range results {
+		if !result.Passed {
+			failing = append(failing, result.Message)
+		}
+	}
+	return failing
+}
+
+// changedFiles lists the paths of changes relative to dir
+func changedFiles(dir string, changes []Change) []string {
+	files := make([]string, 0, len(changes))
+	for _, change := range changes {
+		rel, err := filepath.Rel(dir, change.Path)
+		if err != nil {
+			rel = change.Path
+		}
+		if change.Type == ChangeCreateFolder {
+			rel += "/"
+		}
+		files = append(files, filepath.ToSlash(rel))
+	}
+	return files
+}
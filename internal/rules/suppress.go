@@ -0,0 +1,68 @@
+package rules
+
+import (
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/m-mdy-m/psx/internal/config"
+	"github.com/m-mdy-m/psx/internal/logger"
+)
+
+// applySuppressions marks failed results covered by a suppression that
+// hasn't expired, and records every suppression that matched in used
+func applySuppressions(results []RuleResult, suppressions []config.Suppression, now time.Time, used map[config.Suppression]bool) {
+	for i := range results {
+		result := &results[i]
+		if result.Passed || result.Suppressed {
+			continue
+		}
+
+		target := resultPath(result)
+		for _, s := range suppressions {
+			if !s.Matches(result.RuleID, target) || s.Expired(now) {
+				continue
+			}
+			logger.Verbose(fmt.Sprintf("Suppressed %s: %s", s, s.Reason))
+			result.Suppressed = true
+			result.SuppressionReason = s.Reason
+			used[s] = true
+			break
+		}
+	}
+}
+
+// resultPath is the path of a finding relative to the project root
+func resultPath(result *RuleResult) string {
+	switch {
+	case result.Scope == "":
+		return result.Path
+	case result.Path == "":
+		return result.Scope
+	default:
+		return path.Join(result.Scope, result.Path)
+	}
+}
+
+// allSuppressions lists the suppressions of cfg and its nested scopes once
+// each; scopes inherit their parent's list
+func allSuppressions(cfg *config.Config) []config.Suppression {
+	seen := map[config.Suppression]bool{}
+	all := []config.Suppression{}
+
+	var walk func(*config.Config)
+	walk = func(c *config.Config) {
+		for _, s := range c.Suppressions {
+			if !seen[s] {
+				seen[s] = true
+				all = append(all, s)
+			}
+		}
+		for _, scope := range c.Scopes {
+			walk(scope)
+		}
+	}
+	walk(cfg)
+
+	return all
+}
@@ -2,6 +2,7 @@ package rules
 
 import (
 	"github.com/m-mdy-m/psx/internal/config"
+	"github.com/m-mdy-m/psx/internal/history"
 	"github.com/m-mdy-m/psx/internal/resources"
 )
 
@@ -13,6 +14,7 @@ type Context struct {
 	ProjectType string
 	ProjectInfo *resources.ProjectInfo
 	Config      *config.Config
+	Scope       string // nested config directory, "" for the project root
 }
 type RuleResult struct {
 	RuleID   string
@@ -21,19 +23,31 @@ type RuleResult struct {
 	Message  string
 	FixHint  string
 	DocURL   string
+	Path     string
+	Scope    string // nested config directory the rule ran in
+	Source   string // config file that decided the severity
+
+	Suppressed        bool // failed, but covered by a suppression
+	SuppressionReason string
+	Escalation        *config.Escalation // next scheduled severity change
 }
 type ExecutionResult struct {
 	Context *Context
 	Results []RuleResult
 	Summary Summary
 	Status  Status
+
+	ExpiredSuppressions []config.Suppression
+	UnusedSuppressions  []config.Suppression
+	Drift               []Drift // generated files whose template has changed
 }
 type Summary struct {
-	Total    int
-	Passed   int
-	Errors   int
-	Warnings int
-	Info     int
+	Total      int
+	Passed     int
+	Errors     int
+	Warnings   int
+	Info       int
+	Suppressed int
 }
 type Status string
 
@@ -45,6 +59,7 @@ const (
 
 type FixResult struct {
 	RuleID  string
+	Scope   string // nested config directory the rule was fixed in
 	Fixed   bool
 	Skipped bool
 	Error   error
@@ -55,6 +70,7 @@ type Change struct {
 	Path        string
 	Description string
 	Content     string
+	Original    string // content before a ChangeModifyFile or ChangeDeleteFile
 }
 type ChangeType string
 
@@ -62,6 +78,7 @@ const (
 	ChangeCreateFile   ChangeType = "create_file"
 	ChangeCreateFolder ChangeType = "create_folder"
 	ChangeModifyFile   ChangeType = "modify_file"
+	ChangeDeleteFile   ChangeType = "delete_file"
 )
 
 type FixContext struct {
@@ -69,4 +86,6 @@ type FixContext struct {
 	Interactive   bool
 	DryRun        bool
 	CreateBackups bool
+	Plan          *Plan            // collects the writes until they are applied
+	Journal       *history.Journal // applies the plan and records it for `psx undo`
 }
@@ -0,0 +1,195 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/m-mdy-m/psx/internal/logger"
+	"github.com/m-mdy-m/psx/internal/managed"
+	"github.com/m-mdy-m/psx/internal/resources"
+	"github.com/m-mdy-m/psx/internal/utils"
+)
+
+// Drift is a generated file whose template has changed since psx wrote it
+type Drift struct {
+	Path     string // relative to the project root
+	Template string
+	Edited   bool // changed locally since it was generated
+}
+
+// templateUpdate is a drifted file with the three versions a merge needs
+type templateUpdate struct {
+	entry   managed.Entry
+	base    string // what psx generated
+	local   string // what the file is now
+	updated string // what the template generates now
+}
+
+// CheckDrift compares the files psx generated with what their templates
+// generate now. Files that were deleted, or whose template no longer
+// generates them, aren't reported
+func CheckDrift(ctx *Context) ([]Drift, error) {
+	updates, err := templateUpdates(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	drift := make([]Drift, 0, len(updates))
+	for _, u := range updates {
+		drift = append(drift, Drift{
+			Path:     u.entry.Path,
+			Template: u.entry.Template,
+			Edited:   u.local != u.base,
+		})
+	}
+	return drift, nil
+}
+
+// Update brings the files psx generated up to date with their templates,
+// only those of ruleID when it isn't "". Local edits are kept with a
+// three-way merge against the content psx generated; where they overlap
+// with template changes both are written between conflict markers
+func Update(fixCtx *FixContext, ruleID string) ([]*FixResult, error) {
+	updates, err := templateUpdates(fixCtx.Context, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := []string{}
+	byTemplate := map[string][]templateUpdate{}
+	for _, u := range updates {
+		if _, ok := byTemplate[u.entry.Template]; !ok {
+			templates = append(templates, u.entry.Template)
+		}
+		byTemplate[u.entry.Template] = append(byTemplate[u.entry.Template], u)
+	}
+
+	results := make([]*FixResult, 0, len(templates))
+	for _, template := range templates {
+		// like a fix, a template whose files can't all be updated updates none
+		mark := fixCtx.Plan.begin(template)
+		result, err := updateFiles(template, byTemplate[template], fixCtx)
+		if err != nil {
+			fixCtx.Plan.discard(mark)
+			result = &FixResult{RuleID: template, Error: err}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func updateFiles(template string, updates []templateUpdate, fixCtx *FixContext) (*FixResult, error) {
+	changes := []Change{}
+	for _, u := range updates {
+		merged, conflicts := u.updated, 0
+		if u.local != u.base {
+			merged, conflicts = utils.Merge(u.base, u.local, u.updated)
+		}
+
+		fullPath := filepath.Join(fixCtx.Context.ProjectPath, filepath.FromSlash(u.entry.Path))
+		description := fmt.Sprintf("Update %s from its template", u.entry.Path)
+		if !fixCtx.DryRun {
+			if err := fixCtx.Plan.WriteFile(fullPath, []byte(merged)); err != nil {
+				return nil, fmt.Errorf("%s: %w", u.entry.Path, err)
+			}
+			fixCtx.Plan.Manage(fullPath, u.entry, []byte(u.updated))
+			description = fmt.Sprintf("Updated %s from its template", u.entry.Path)
+		}
+		switch {
+		case conflicts == 1:
+			description += " (1 conflict to resolve)"
+		case conflicts > 1:
+			description += fmt.Sprintf(" (%d conflicts to resolve)", conflicts)
+		}
+
+		changes = append(changes, Change{
+			Type:        ChangeModifyFile,
+			Path:        fullPath,
+			Description: description,
+			Content:     merged,
+			Original:    u.local,
+		})
+	}
+	return &FixResult{RuleID: template, Fixed: true, Changes: changes}, nil
+}
+
+// templateUpdates finds the generated files of the project at ctx whose
+// template has changed
+func templateUpdates(ctx *Context, ruleID string) ([]templateUpdate, error) {
+	m, err := managed.Load(ctx.ProjectPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Files) == 0 {
+		return nil, nil
+	}
+	// generate the files again with the answers they were generated with
+	utils.DefaultAnswers(m.Answers)
+	defer resources.SetTemplateDate(time.Time{})
+
+	updates := []templateUpdate{}
+	for _, entry := range m.Files {
+		if ruleID != "" && entry.Template != ruleID {
+			continue
+		}
+		local, err := os.ReadFile(filepath.Join(ctx.ProjectPath, filepath.FromSlash(entry.Path)))
+		if errors.Is(err, os.ErrNotExist) {
+			logger.Verbose(fmt.Sprintf("%s was deleted, not checking its template", entry.Path))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		updated, ok := regenerate(ctx, entry)
+		if !ok {
+			logger.Verbose(fmt.Sprintf("The %s template no longer generates %s", entry.Template, entry.Path))
+			continue
+		}
+		if managed.Hash([]byte(updated)) == entry.Hash {
+			continue
+		}
+
+		base, err := managed.Base(ctx.ProjectPath, entry)
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, templateUpdate{entry: entry, base: base, local: string(local), updated: updated})
+	}
+	return updates, nil
+}
+
+// regenerate returns what the template of entry generates now, as of the
+// day the file was generated so dates in it don't count as changes
+func regenerate(ctx *Context, entry managed.Entry) (string, bool) {
+	date, _ := time.ParseInLocation(managed.DateFormat, entry.Date, time.Local)
+	resources.SetTemplateDate(date)
+
+	// paths the templates generate are relative to the scope they ran in
+	rel := entry.Path
+	projectPath := ctx.ProjectPath
+	if entry.Scope != "" {
+		rel = strings.TrimPrefix(rel, entry.Scope+"/")
+		projectPath = filepath.Join(projectPath, filepath.FromSlash(entry.Scope))
+	}
+	fixer := NewFixer(&Context{
+		ProjectPath: projectPath,
+		ProjectType: entry.Type,
+		ProjectInfo: ctx.ProjectInfo,
+		Config:      ctx.Config,
+		Scope:       entry.Scope,
+	})
+
+	if fixer.resolver.IsSpecialMultiFileRule(entry.Template) || fixer.needsMultiFileGeneration(entry.Template) {
+		files, err := fixer.generator.GenerateMultiple(entry.Template)
+		if err == nil && len(files) > 0 {
+			content, ok := files[rel]
+			return content, ok
+		}
+	}
+	content, err := fixer.generator.Generate(entry.Template, rel)
+	return content, err == nil
+}
@@ -0,0 +1,42 @@
+package rules
+
+import (
+	"github.com/m-mdy-m/psx/internal/config"
+)
+
+// Verify checks the rules of applied fixes again, in the scope each was
+// fixed in, and returns those that still fail. A fix that doesn't satisfy
+// its rule, e.g. one that writes a file the rule's patterns don't match,
+// shows up here rather than in the next 'psx check'
+func Verify(cfg *config.Config, ctx *Context, results []*FixResult) []RuleResult {
+	fixed := map[string][]string{}
+	for _, result := range results {
+		if result.Fixed && result.Error == nil {
+			fixed[result.Scope] = append(fixed[result.Scope], result.RuleID)
+		}
+	}
+	return verify(cfg, ctx, fixed)
+}
+
+func verify(cfg *config.Config, ctx *Context, fixed map[string][]string) []RuleResult {
+	failing := []RuleResult{}
+	engine := NewEngine(cfg, ctx)
+	for _, ruleID := range fixed[ctx.Scope] {
+		rule, exists := cfg.ActiveRules[ruleID]
+		if !exists {
+			// custom files and folders have no rule of their own
+			continue
+		}
+		for _, result := range engine.checkRule(ruleID, rule) {
+			if !result.Passed {
+				result.Scope = ctx.Scope
+				failing = append(failing, result)
+			}
+		}
+	}
+
+	for _, scope := range cfg.Scopes {
+		failing = append(failing, verify(scope, ScopeContext(ctx, scope), fixed)...)
+	}
+	return failing
+}
@@ -0,0 +1,190 @@
+package utils
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Questions are what psx asks while generating files, by key, with the
+// answers each allows (any when empty). The key lets a question be answered
+// ahead of time: answers: in psx.yml, an answers file or --answer key=value
+var Questions = map[string][]string{
+	"project_name":      nil,
+	"description":       nil,
+	"author":            nil,
+	"email":             nil,
+	"github_user":       nil,
+	"repo_name":         nil,
+	"license":           nil,
+	"docker_compose_db": {"yes", "no"},
+	"ci_platform":       {"github", "gitlab", "both", "skip"},
+}
+
+// Choice is an option of a choice question: the value it is answered with
+// and the label shown when asking
+type Choice struct {
+	Value string
+	Label string
+}
+
+var (
+	answers        = map[string]string{}
+	nonInteractive bool
+	unanswered     []string
+)
+
+// UseAnswers sets the answers questions get instead of prompting. Later
+// calls add to and override earlier ones
+func UseAnswers(values map[string]string) {
+	for key, value := range values {
+		answers[key] = normalizeAnswer(key, value)
+	}
+}
+
+// DefaultAnswers sets the answers of the questions that don't have one
+// yet, leaving those given already
+func DefaultAnswers(values map[string]string) {
+	for key, value := range values {
+		if _, ok := answers[key]; !ok {
+			answers[key] = normalizeAnswer(key, value)
+		}
+	}
+}
+
+// Answers returns every answer given so far, ahead of time or at a prompt
+func Answers() map[string]string {
+	return maps.Clone(answers)
+}
+
+// SetNonInteractive makes questions without an answer return their
+// default and be listed by Unanswered instead of reading stdin
+func SetNonInteractive(enabled bool) {
+	nonInteractive = enabled
+}
+
+// Unanswered lists the keys asked without an answer in non-interactive
+// mode, sorted
+func Unanswered() []string {
+	keys := slices.Clone(unanswered)
+	sort.Strings(keys)
+	return slices.Compact(keys)
+}
+
+// ValidateAnswer checks key is a known question and value one of its
+// choices
+func ValidateAnswer(key, value string) error {
+	choices, ok := Questions[key]
+	if !ok {
+		known := make([]string, 0, len(Questions))
+		for k := range Questions {
+			known = append(known, k)
+		}
+		if suggestion := Suggest(key, known); suggestion != "" {
+			return fmt.Errorf("unknown answer '%s' - did you mean '%s'?", key, suggestion)
+		}
+		return fmt.Errorf("unknown answer '%s'", key)
+	}
+	if len(choices) > 0 && !slices.Contains(choices, normalizeAnswer(key, value)) {
+		return fmt.Errorf("invalid answer '%s' for %s - use %s", value, key, strings.Join(choices, ", "))
+	}
+	return nil
+}
+
+// ParseAnswer splits a key=value answer
+func ParseAnswer(s string) (string, string, error) {
+	key, value, ok := strings.Cut(s, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("invalid answer '%s' - use key=value", s)
+	}
+	value = strings.TrimSpace(value)
+	if err := ValidateAnswer(key, value); err != nil {
+		return "", "", err
+	}
+	return key, normalizeAnswer(key, value), nil
+}
+
+// normalizeAnswer accepts true/false, as YAML writes them, for yes/no
+func normalizeAnswer(key, value string) string {
+	if !slices.Equal(Questions[key], []string{"yes", "no"}) {
+		return value
+	}
+	switch strings.ToLower(value) {
+	case "yes", "y", "true":
+		return "yes"
+	case "no", "n", "false":
+		return "no"
+	}
+	return value
+}
+
+// AskInput answers the question key, prompting with message and
+// defaultValue when it has no answer yet
+func AskInput(key, message, defaultValue string) string {
+	if value, ok := answer(key); ok {
+		return value
+	}
+	if missing(key) {
+		return defaultValue
+	}
+	value := PromptInput(message, defaultValue)
+	answers[key] = value
+	return value
+}
+
+// AskConfirm answers the yes/no question key, prompting with message when
+// it has no answer yet
+func AskConfirm(key, message string) bool {
+	if value, ok := answer(key); ok {
+		return value == "yes"
+	}
+	if missing(key) {
+		return false
+	}
+	yes := Prompt(message)
+	answers[key] = map[bool]string{true: "yes", false: "no"}[yes]
+	return yes
+}
+
+// AskChoice answers the question key with the value of one of choices,
+// prompting with message when it has no answer yet
+func AskChoice(key, message string, choices []Choice) string {
+	if value, ok := answer(key); ok {
+		return value
+	}
+	if missing(key) {
+		return choices[0].Value
+	}
+
+	labels := make([]string, len(choices))
+	for i, choice := range choices {
+		labels[i] = choice.Label
+	}
+	label, _ := PromptChoice(message, labels)
+	value := choices[0].Value
+	for _, choice := range choices {
+		if choice.Label == label {
+			value = choice.Value
+		}
+	}
+	answers[key] = value
+	return value
+}
+
+// answer returns the answer given for key; an interactive answer is kept,
+// so a question is asked once per run
+func answer(key string) (string, bool) {
+	value, ok := answers[key]
+	return value, ok
+}
+
+// missing records key as unanswered when psx can't prompt for it
+func missing(key string) bool {
+	if nonInteractive {
+		unanswered = append(unanswered, key)
+	}
+	return nonInteractive
+}
@@ -0,0 +1,239 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// DiffContext is the number of unchanged lines shown around each change
+const DiffContext = 3
+
+// noNewline marks a last line that has no newline, so it differs from the
+// same line with one and is printed with git's marker
+const noNewline = "\x00"
+
+// DiffOp is one line of a line diff: ' ' unchanged, '-' removed, '+' added
+type DiffOp struct {
+	Kind byte
+	Line string
+}
+
+// UnifiedDiff returns a unified diff from before to after, "" when they are
+// the same. The names are used in the ---/+++ header
+func UnifiedDiff(oldName, newName, before, after string) string {
+	ops := DiffLines(splitLines(before), splitLines(after))
+
+	changed := false
+	for _, op := range ops {
+		if op.Kind != ' ' {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for _, hunk := range diffHunks(ops) {
+		b.WriteString(hunk)
+	}
+	return b.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	if !strings.HasSuffix(s, "\n") {
+		s += noNewline
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// DiffLines computes a shortest edit script from a to b with Myers'
+// algorithm
+func DiffLines(a, b []string) []DiffOp {
+	n, m := len(a), len(b)
+	limit := n + m
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+
+	// trace[d] holds v[-d..d] as it was at the start of round d, which is
+	// all the backtracking needs
+	trace := [][]int{}
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b)
+			}
+		}
+	}
+	return nil
+}
+
+func backtrack(trace [][]int, a, b []string) []DiffOp {
+	ops := []DiffOp{}
+	x, y := len(a), len(b)
+
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d] }
+
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, DiffOp{' ', a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			ops = append(ops, DiffOp{'+', b[y-1]})
+			y--
+		} else {
+			ops = append(ops, DiffOp{'-', a[x-1]})
+			x--
+		}
+	}
+	for x > 0 && y > 0 {
+		ops = append(ops, DiffOp{' ', a[x-1]})
+		x--
+		y--
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// diffHunks groups changes that are close together, with DiffContext
+// unchanged lines around them
+func diffHunks(ops []DiffOp) []string {
+	hunks := []string{}
+
+	for start := 0; start < len(ops); {
+		// find the next change
+		first := start
+		for first < len(ops) && ops[first].Kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+
+		// extend while the next change is within 2*DiffContext lines
+		last := first
+		for i := first; i < len(ops); i++ {
+			if ops[i].Kind != ' ' {
+				last = i
+			} else if i-last > 2*DiffContext {
+				break
+			}
+		}
+
+		from := max(first-DiffContext, start)
+		to := min(last+DiffContext+1, len(ops))
+		hunks = append(hunks, formatHunk(ops, from, to))
+		start = to
+	}
+	return hunks
+}
+
+func formatHunk(ops []DiffOp, from, to int) string {
+	oldLine, newLine := 1, 1
+	for _, op := range ops[:from] {
+		if op.Kind != '+' {
+			oldLine++
+		}
+		if op.Kind != '-' {
+			newLine++
+		}
+	}
+
+	oldCount, newCount := 0, 0
+	var body strings.Builder
+	for _, op := range ops[from:to] {
+		if op.Kind != '+' {
+			oldCount++
+		}
+		if op.Kind != '-' {
+			newCount++
+		}
+		body.WriteByte(op.Kind)
+		body.WriteString(strings.TrimSuffix(op.Line, noNewline))
+		body.WriteByte('\n')
+		if strings.HasSuffix(op.Line, noNewline) {
+			body.WriteString("\\ No newline at end of file\n")
+		}
+	}
+
+	// an empty range is given as the line before it
+	if oldCount == 0 {
+		oldLine--
+	}
+	if newCount == 0 {
+		newLine--
+	}
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@\n%s", oldLine, oldCount, newLine, newCount, body.String())
+}
+
+// DiffStat counts the lines added and removed from before to after
+func DiffStat(before, after string) (added, removed int) {
+	for _, op := range DiffLines(splitLines(before), splitLines(after)) {
+		switch op.Kind {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// ColorDiff colors a unified diff like git does: headers bold, hunk
+// ranges cyan, removed lines red and added lines green
+func ColorDiff(diff string) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		text := strings.TrimSuffix(line, "\n")
+		switch {
+		case text == "":
+		case strings.HasPrefix(text, "--- "), strings.HasPrefix(text, "+++ "):
+			text = color.New(color.Bold).Sprint(text)
+		case strings.HasPrefix(text, "@@"), strings.HasPrefix(text, "\\"):
+			text = color.CyanString("%s", text)
+		case text[0] == '-':
+			text = color.RedString("%s", text)
+		case text[0] == '+':
+			text = color.GreenString("%s", text)
+		}
+		b.WriteString(text)
+		if strings.HasSuffix(line, "\n") {
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
@@ -1,9 +1,13 @@
 package utils
 
 import (
+	"bytes"
 	"embed"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/goccy/go-yaml"
 	"github.com/m-mdy-m/psx/internal/logger"
@@ -37,6 +41,15 @@ func CreateDir(path string) error {
 	return nil
 }
 
+// NewFileMode is the mode of a file psx creates: scripts that start with a
+// shebang are executable
+func NewFileMode(content []byte) os.FileMode {
+	if bytes.HasPrefix(content, []byte("#!")) {
+		return 0755
+	}
+	return 0644
+}
+
 func IsDirEmpty(path string) (bool, error) {
 	entries, err := os.ReadDir(path)
 	if err != nil {
@@ -57,3 +70,75 @@ func LoadEmbedded[T any](name, path string, fs embed.FS) (*T, error) {
 
 	return &result, nil
 }
+
+// Glob matches a slash-separated pattern against the tree under root and
+// returns the matches relative to root. Unlike filepath.Glob, "**" matches
+// any number of directories
+func Glob(root, pattern string) ([]string, error) {
+	pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+
+	if !strings.Contains(pattern, "**") {
+		matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, err
+		}
+		result := make([]string, 0, len(matches))
+		for _, match := range matches {
+			if rel, err := filepath.Rel(root, match); err == nil {
+				result = append(result, filepath.ToSlash(rel))
+			}
+		}
+		return result, nil
+	}
+
+	if _, err := path.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+		return nil, err
+	}
+
+	result := []string{}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == root {
+			return nil
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if MatchGlob(pattern, rel) {
+			result = append(result, rel)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// MatchGlob reports whether a slash-separated name matches pattern, where
+// "**" stands for zero or more path segments
+func MatchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
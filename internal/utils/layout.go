@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+var lineComment = regexp.MustCompile(`\s+#.*$`)
+
+// KeepLayout undoes what re-printing a parsed YAML file changes besides the
+// edit itself: the spacing before inline comments, and comments the printer
+// drops. Lines the edit didn't change are taken from before; a changed line
+// gets back the comment of the line it replaced, found by its key (or value,
+// for a renamed key) rather than its position, as lines the edit added
+// shift the others
+func KeepLayout(before, after string) string {
+	lines := func(s string) []string { return strings.Split(strings.TrimSuffix(s, "\n"), "\n") }
+
+	ops := DiffLines(lines(before), lines(after))
+	out := []string{}
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == ' ' {
+			out = append(out, ops[i].Line)
+			i++
+			continue
+		}
+
+		removed, added := []string{}, []string{}
+		for ; i < len(ops) && ops[i].Kind == '-'; i++ {
+			removed = append(removed, ops[i].Line)
+		}
+		for ; i < len(ops) && ops[i].Kind == '+'; i++ {
+			added = append(added, ops[i].Line)
+		}
+		out = append(out, restoreLines(removed, added)...)
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+// restoreLines pairs each added line with a removed one: the same line
+// apart from comment spacing, which is kept as it was, or else the line with
+// the same key and indentation, or the same value under a renamed key, whose
+// comment it gets back
+func restoreLines(removed, added []string) []string {
+	normalize := func(line string) string { return lineComment.ReplaceAllStringFunc(line, strings.TrimSpace) }
+	split := func(line string) (key, value string, ok bool) {
+		return strings.Cut(lineComment.ReplaceAllString(line, ""), ":")
+	}
+	indent := func(line string) string { return line[:len(line)-len(strings.TrimLeft(line, " "))] }
+
+	used := make([]bool, len(removed))
+	find := func(match func(old string) bool) int {
+		for j, old := range removed {
+			if !used[j] && match(old) {
+				used[j] = true
+				return j
+			}
+		}
+		return -1
+	}
+
+	out := make([]string, 0, len(added))
+	for _, line := range added {
+		if j := find(func(old string) bool { return normalize(old) == normalize(line) }); j >= 0 {
+			out = append(out, removed[j])
+			continue
+		}
+		key, value, ok := split(line)
+		if !ok {
+			out = append(out, line)
+			continue
+		}
+		j := find(func(old string) bool { k, _, ok := split(old); return ok && k == key })
+		if j < 0 {
+			j = find(func(old string) bool {
+				_, v, ok := split(old)
+				return ok && indent(old) == indent(line) && strings.TrimSpace(v) == strings.TrimSpace(value) && v != ""
+			})
+		}
+		if j >= 0 {
+			line = restoreComment(removed[j], line)
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// restoreComment gives line the comment of old, spacing included, when it
+// has none or the same one
+func restoreComment(old, line string) string {
+	comment := lineComment.FindString(old)
+	if comment == "" {
+		return line
+	}
+	if current := lineComment.FindString(line); current != "" {
+		if strings.TrimSpace(current) != strings.TrimSpace(comment) {
+			return line
+		}
+		line = strings.TrimSuffix(line, current)
+	}
+	return line + comment
+}
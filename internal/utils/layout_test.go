@@ -0,0 +1,167 @@
+package utils
+
+import "testing"
+
+func TestKeepLayout(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string // the file as written
+		after  string // the file as the YAML printer writes it after the edit
+		want   string
+	}{
+		{
+			name: "new key before a flow list",
+			before: `rules:
+  readme: error
+ignore: [node_modules, dist]  # flow list
+`,
+			after: `rules:
+  readme: error
+  changelog: warning
+ignore: [node_modules, dist] # flow list
+`,
+			want: `rules:
+  readme: error
+  changelog: warning
+ignore: [node_modules, dist]  # flow list
+`,
+		},
+		{
+			name: "new key before a line that lost its comment",
+			before: `rules:
+  readme: error
+ignore: [node_modules, dist]  # flow list
+`,
+			after: `rules:
+  readme: error
+  changelog: warning
+ignore: [node_modules, dist]
+`,
+			want: `rules:
+  readme: error
+  changelog: warning
+ignore: [node_modules, dist]  # flow list
+`,
+		},
+		{
+			name: "comment on the changed line",
+			before: `rules:
+  readme: error   # must have
+  license: warning
+`,
+			after: `rules:
+  readme: warning
+  license: warning
+`,
+			want: `rules:
+  readme: warning   # must have
+  license: warning
+`,
+		},
+		{
+			name: "changed line keeps its own comment",
+			before: `rules:
+  readme: error  # must have
+`,
+			after: `rules:
+  readme: warning # required
+`,
+			want: `rules:
+  readme: warning # required
+`,
+		},
+		{
+			name: "nested mappings",
+			before: `rules:
+  license:
+    warning: now  # soon
+    error: 2027-01-01  # later
+  readme: error  # must have
+`,
+			after: `rules:
+  license:
+    warning: now # soon
+    error: now
+  readme: error # must have
+`,
+			want: `rules:
+  license:
+    warning: now  # soon
+    error: now  # later
+  readme: error  # must have
+`,
+		},
+		{
+			name: "same key under another parent",
+			before: `rules:
+  license:
+    error: 2027-01-01  # license
+ignore: [dist]  # flow list
+`,
+			after: `rules:
+  license:
+    error: now
+  changelog:
+    error: now
+ignore: [dist]
+`,
+			want: `rules:
+  license:
+    error: now  # license
+  changelog:
+    error: now
+ignore: [dist]  # flow list
+`,
+		},
+		{
+			name: "changed value, same comment",
+			before: `version: 1  # format
+`,
+			after: `version: 2 # format
+`,
+			want: `version: 2  # format
+`,
+		},
+		{
+			name: "renamed key",
+			before: `rules:
+  old_readme: error   # must have
+  license: warning
+`,
+			after: `rules:
+  readme: error # must have
+  license: warning
+`,
+			want: `rules:
+  readme: error   # must have
+  license: warning
+`,
+		},
+		{
+			name: "unchanged file",
+			before: `version: 1
+# rules
+rules:
+  readme: error   # must have
+`,
+			after: `version: 1
+# rules
+rules:
+  readme: error # must have
+`,
+			want: `version: 1
+# rules
+rules:
+  readme: error   # must have
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KeepLayout(tt.before, tt.after); got != tt.want {
+				t.Errorf("KeepLayout() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package utils
+
+import "strings"
+
+// Conflict markers of Merge, as git writes them
+const (
+	ConflictLocal    = "<<<<<<< local"
+	ConflictSplit    = "======="
+	ConflictTemplate = ">>>>>>> template"
+)
+
+// edit replaces the lines [start, end) of the base with lines
+type edit struct {
+	start, end int
+	lines      []string
+}
+
+// Merge combines the changes made to base in local and in updated, line by
+// line. Changes that touch the same lines and differ are kept both, between
+// conflict markers, and counted in conflicts
+func Merge(base, local, updated string) (merged string, conflicts int) {
+	baseLines := splitLines(base)
+	ours := edits(DiffLines(baseLines, splitLines(local)))
+	theirs := edits(DiffLines(baseLines, splitLines(updated)))
+
+	out := []string{}
+	pos := 0
+	for len(ours) > 0 || len(theirs) > 0 {
+		// the next group of edits: everything that overlaps or touches the
+		// earliest one, from either side
+		start, end := nextStart(ours, theirs), -1
+		var mine, their []edit
+		for {
+			grew := false
+			if len(ours) > 0 && ours[0].start <= max(end, start) {
+				end = max(end, ours[0].end)
+				mine, ours = append(mine, ours[0]), ours[1:]
+				grew = true
+			}
+			if len(theirs) > 0 && theirs[0].start <= max(end, start) {
+				end = max(end, theirs[0].end)
+				their, theirs = append(their, theirs[0]), theirs[1:]
+				grew = true
+			}
+			if !grew {
+				break
+			}
+		}
+
+		out = append(out, baseLines[pos:start]...)
+		a := applyEdits(baseLines, start, end, mine)
+		b := applyEdits(baseLines, start, end, their)
+		switch {
+		case len(their) == 0 || sameLines(a, b):
+			out = append(out, a...)
+		case len(mine) == 0:
+			out = append(out, b...)
+		default:
+			conflicts++
+			out = append(out, ConflictLocal)
+			out = append(out, markLast(a)...)
+			out = append(out, ConflictSplit)
+			out = append(out, markLast(b)...)
+			out = append(out, ConflictTemplate)
+		}
+		pos = end
+	}
+	out = append(out, baseLines[pos:]...)
+
+	return joinLines(out), conflicts
+}
+
+// edits turns a line diff against the base into the ranges it replaces
+func edits(ops []DiffOp) []edit {
+	result := []edit{}
+	var current *edit
+	i := 0
+	for _, op := range ops {
+		switch op.Kind {
+		case ' ':
+			if current != nil {
+				result = append(result, *current)
+				current = nil
+			}
+			i++
+		case '-':
+			if current == nil {
+				current = &edit{start: i, end: i}
+			}
+			i++
+			current.end = i
+		case '+':
+			if current == nil {
+				current = &edit{start: i, end: i}
+			}
+			current.lines = append(current.lines, op.Line)
+		}
+	}
+	if current != nil {
+		result = append(result, *current)
+	}
+	return result
+}
+
+func nextStart(ours, theirs []edit) int {
+	switch {
+	case len(ours) == 0:
+		return theirs[0].start
+	case len(theirs) == 0:
+		return ours[0].start
+	}
+	return min(ours[0].start, theirs[0].start)
+}
+
+// applyEdits returns the lines [start, end) of base with edits applied
+func applyEdits(base []string, start, end int, edits []edit) []string {
+	lines := []string{}
+	pos := start
+	for _, e := range edits {
+		lines = append(lines, base[pos:e.start]...)
+		lines = append(lines, e.lines...)
+		pos = e.end
+	}
+	return append(lines, base[pos:end]...)
+}
+
+func sameLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// markLast ends a side of a conflict with a newline even when it is the
+// end of its file, as a marker follows it
+func markLast(lines []string) []string {
+	if n := len(lines); n > 0 && strings.HasSuffix(lines[n-1], noNewline) {
+		lines = append(lines[:n-1:n-1], strings.TrimSuffix(lines[n-1], noNewline))
+	}
+	return lines
+}
+
+// joinLines is the reverse of splitLines
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	s := strings.ReplaceAll(strings.Join(lines, "\n"), noNewline+"\n", "\n")
+	if strings.HasSuffix(s, noNewline) {
+		return strings.TrimSuffix(s, noNewline)
+	}
+	return s + "\n"
+}
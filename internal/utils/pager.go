@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Page sends everything written to stdout through $PAGER, less by default,
+// until the returned function is called. Nothing is paged when stdout isn't
+// a terminal or the pager can't be started
+func Page() func() {
+	info, err := os.Stdout.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return func() {}
+	}
+
+	pager := strings.Fields(os.Getenv("PAGER"))
+	if len(pager) == 0 {
+		pager = []string{"less"}
+	}
+	if pager[0] == "cat" {
+		return func() {}
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return func() {}
+	}
+	cmd := exec.Command(pager[0], pager[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = r, os.Stdout, os.Stderr
+	// quit when it fits on one screen and keep colors
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	if err := cmd.Start(); err != nil {
+		r.Close()
+		w.Close()
+		return func() {}
+	}
+	r.Close()
+
+	stdout, output := os.Stdout, color.Output
+	os.Stdout, color.Output = w, w
+	return func() {
+		w.Close()
+		cmd.Wait()
+		os.Stdout, color.Output = stdout, output
+	}
+}
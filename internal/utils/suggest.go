@@ -0,0 +1,42 @@
+package utils
+
+import "strings"
+
+// Levenshtein returns the edit distance between a and b
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// Suggest returns the candidate closest to word, or "" when none is close
+// enough to be a likely typo
+func Suggest(word string, candidates []string) string {
+	word = strings.ToLower(word)
+	limit := max(2, len(word)/3)
+
+	best, bestDistance := "", limit+1
+	for _, candidate := range candidates {
+		distance := Levenshtein(word, strings.ToLower(candidate))
+		if distance < bestDistance || (distance == bestDistance && candidate < best) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}